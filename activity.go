@@ -0,0 +1,90 @@
+package lcm
+
+import (
+	"sync"
+	"time"
+)
+
+// ActivityTracker tracks user activity (e.g. button presses or display
+// updates) and signals when there has been no activity for the
+// duration of the timeout, e.g. to turn the display off.
+//
+//	a := lcm.NewActivityTracker(15 * time.Second)
+//	defer a.Stop()
+//	for {
+//		select {
+//		case <-m.Buttons():
+//			a.Touch()
+//		case <-a.Idle():
+//			m.Send(lcm.DisplayOff)
+//		}
+//	}
+type ActivityTracker struct {
+	timeout time.Duration
+	idleC   chan time.Time
+
+	mu    sync.Mutex
+	timer *time.Timer
+	gen   int // Incremented on Touch, invalidates pending timers.
+}
+
+// NewActivityTracker returns a new ActivityTracker. The inactivity timer
+// starts on the first call to Touch. A zero timeout never goes idle.
+func NewActivityTracker(timeout time.Duration) *ActivityTracker {
+	return &ActivityTracker{
+		timeout: timeout,
+		idleC:   make(chan time.Time, 1),
+	}
+}
+
+// Touch registers activity and resets the inactivity timer.
+func (a *ActivityTracker) Touch() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.timer != nil {
+		a.timer.Stop()
+	}
+	// Discard idle signal that was not yet received, it's stale.
+	select {
+	case <-a.idleC:
+	default:
+	}
+
+	a.gen++
+	if a.timeout <= 0 {
+		return
+	}
+	gen := a.gen
+	a.timer = time.AfterFunc(a.timeout, func() { a.idle(gen) })
+}
+
+func (a *ActivityTracker) idle(gen int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if gen != a.gen {
+		return // Touched after the timer fired.
+	}
+	select {
+	case a.idleC <- time.Now():
+	default:
+	}
+}
+
+// Idle returns a channel that receives the time when the timeout has
+// passed since the last Touch. It receives at most once per Touch.
+func (a *ActivityTracker) Idle() <-chan time.Time {
+	return a.idleC
+}
+
+// Stop the inactivity timer, Idle will not receive until the next Touch.
+func (a *ActivityTracker) Stop() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.timer != nil {
+		a.timer.Stop()
+	}
+	a.gen++
+}
@@ -0,0 +1,58 @@
+package lcm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestActivityTracker(t *testing.T) {
+	a := NewActivityTracker(20 * time.Millisecond)
+	defer a.Stop()
+
+	select {
+	case <-a.Idle():
+		t.Fatal("Idle() before Touch()")
+	case <-time.After(40 * time.Millisecond):
+	}
+
+	start := time.Now()
+	a.Touch()
+	for i := 0; i < 3; i++ {
+		time.Sleep(10 * time.Millisecond)
+		a.Touch()
+	}
+	select {
+	case now := <-a.Idle():
+		if d := now.Sub(start); d < 50*time.Millisecond {
+			t.Errorf("Idle() after %v, want at least 50ms", d)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for Idle()")
+	}
+
+	// Only once per Touch.
+	select {
+	case <-a.Idle():
+		t.Error("Idle() received twice")
+	case <-time.After(40 * time.Millisecond):
+	}
+
+	// Stop prevents the pending idle signal.
+	a.Touch()
+	a.Stop()
+	select {
+	case <-a.Idle():
+		t.Error("Idle() after Stop()")
+	case <-time.After(40 * time.Millisecond):
+	}
+}
+
+func TestActivityTracker_zeroTimeout(t *testing.T) {
+	a := NewActivityTracker(0)
+	a.Touch()
+	select {
+	case <-a.Idle():
+		t.Error("Idle() with zero timeout")
+	case <-time.After(20 * time.Millisecond):
+	}
+}
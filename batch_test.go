@@ -0,0 +1,90 @@
+package lcm_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mafredri/lcm"
+	"github.com/mafredri/lcm/lcmtest"
+)
+
+func TestLCM_SendBatch(t *testing.T) {
+	waiting := make(chan struct{}, 1)
+	first := true
+	m, f := openFake(t, func(msg lcm.Message) []byte {
+		if msg.Function() == lcm.Fclear && first {
+			// Don't reply, the host will wait and retry.
+			first = false
+			waiting <- struct{}{}
+			return nil
+		}
+		return lcmtest.ReplyOk(msg)
+	})
+
+	errc := make(chan error, 1)
+	go func() { errc <- m.SendBatch(lcm.DisplayOn, lcm.ClearDisplay, lcm.DisplayStatus) }()
+	<-waiting
+
+	err := m.Send(lcm.DisplayOff)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if err = <-errc; err != nil {
+		t.Fatalf("SendBatch() error = %v", err)
+	}
+
+	var got []lcm.Function
+	for _, msg := range f.Received() {
+		if msg.Function() == 0x00 { // Flush.
+			continue
+		}
+		if len(got) > 0 && got[len(got)-1] == msg.Function() {
+			continue // Retry.
+		}
+		got = append(got, msg.Function())
+	}
+	want := []lcm.Function{lcm.Fon, lcm.Fclear, lcm.Fstatus, lcm.Fon}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("received %v, want %v", got, want)
+	}
+}
+
+func TestLCM_SendBatch_Error(t *testing.T) {
+	m, f := openFake(t, func(msg lcm.Message) []byte {
+		if msg.Function() == lcm.Fclear {
+			return lcmtest.ReplyError(msg)
+		}
+		return lcmtest.ReplyOk(msg)
+	})
+
+	err := m.SendBatch(lcm.DisplayOn, lcm.ClearDisplay, lcm.DisplayStatus)
+	var batchErr *lcm.BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("SendBatch() error = %v, want *lcm.BatchError", err)
+	}
+	if batchErr.Index != 1 {
+		t.Errorf("BatchError.Index = %d, want 1", batchErr.Index)
+	}
+	for _, msg := range f.Received() {
+		if msg.Function() == lcm.Fstatus {
+			t.Error("message after failed message was sent")
+		}
+	}
+}
+
+func TestLCM_SendBatch_Empty(t *testing.T) {
+	m, f := openFake(t, nil)
+
+	if err := m.SendBatch(); err != nil {
+		t.Errorf("SendBatch() error = %v", err)
+	}
+
+	// The handler is still alive.
+	if err := m.Send(lcm.DisplayOn); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.Received(); len(got) != 1 {
+		t.Errorf("received %d frames, want 1", len(got))
+	}
+}
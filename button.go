@@ -0,0 +1,64 @@
+package lcm
+
+import "time"
+
+// DefaultChordWindow is the default window in which two button presses
+// are considered a chord. The MCU serializes button presses, so even
+// buttons that are pressed simultaneously arrive one after the other.
+const DefaultChordWindow = 150 * time.Millisecond
+
+// Chord represents two different buttons pressed in quick succession.
+// Chords are order-insensitive, use NewChord for constructing them.
+type Chord struct {
+	A, B Button
+}
+
+// NewChord returns the chord for the two buttons.
+func NewChord(a, b Button) Chord {
+	if b < a {
+		a, b = b, a
+	}
+	return Chord{A: a, B: b}
+}
+
+// String implements fmt.Stringer.
+func (c Chord) String() string {
+	return c.A.String() + "+" + c.B.String()
+}
+
+// ChordDetector detects chords in a stream of button presses. It does
+// not delay or swallow button presses, each press should still be
+// handled as usual, the chord is reported in addition to the presses.
+//
+//	d := lcm.NewChordDetector(lcm.DefaultChordWindow)
+//	for btn := range m.Buttons() {
+//		handle(btn)
+//		if c, ok := d.Press(btn, time.Now()); ok && c == lcm.NewChord(lcm.Up, lcm.Down) {
+//			showDebug()
+//		}
+//	}
+type ChordDetector struct {
+	window time.Duration
+	last   Button
+	lastAt time.Time
+}
+
+// NewChordDetector returns a new ChordDetector that reports chords for
+// button presses within window of each other.
+func NewChordDetector(window time.Duration) *ChordDetector {
+	return &ChordDetector{window: window}
+}
+
+// Press registers a button press at time t and reports if it completed
+// a chord. A button press can only be part of one chord.
+func (d *ChordDetector) Press(b Button, t time.Time) (Chord, bool) {
+	last, lastAt := d.last, d.lastAt
+	d.last, d.lastAt = b, t
+
+	if last == 0 || last == b || t.Sub(lastAt) > d.window {
+		return Chord{}, false
+	}
+
+	d.last = 0 // Reset, this press completed the chord.
+	return NewChord(last, b), true
+}
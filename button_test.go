@@ -0,0 +1,57 @@
+package lcm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChordDetector_Press(t *testing.T) {
+	type press struct {
+		b     Button
+		after time.Duration
+	}
+	tests := []struct {
+		name    string
+		presses []press
+		want    []Chord
+	}{
+		{
+			name:    "Chord",
+			presses: []press{{Up, 0}, {Down, 50 * time.Millisecond}},
+			want:    []Chord{{}, {Up, Down}},
+		},
+		{
+			name:    "Chord reverse order",
+			presses: []press{{Down, 0}, {Up, 50 * time.Millisecond}},
+			want:    []Chord{{}, {Up, Down}},
+		},
+		{
+			name:    "Outside window",
+			presses: []press{{Up, 0}, {Down, 200 * time.Millisecond}},
+			want:    []Chord{{}, {}},
+		},
+		{
+			name:    "Same button",
+			presses: []press{{Up, 0}, {Up, 50 * time.Millisecond}},
+			want:    []Chord{{}, {}},
+		},
+		{
+			name:    "Press is part of one chord",
+			presses: []press{{Up, 0}, {Down, 50 * time.Millisecond}, {Enter, 50 * time.Millisecond}},
+			want:    []Chord{{}, {Up, Down}, {}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewChordDetector(DefaultChordWindow)
+			now := time.Now()
+			for i, p := range tt.presses {
+				now = now.Add(p.after)
+				got, ok := d.Press(p.b, now)
+				if got != tt.want[i] || ok != (tt.want[i] != Chord{}) {
+					t.Errorf("ChordDetector.Press() press %d = %v, %v, want %v", i, got, ok, tt.want[i])
+				}
+			}
+		})
+	}
+}
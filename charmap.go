@@ -0,0 +1,76 @@
+package lcm
+
+import (
+	"fmt"
+)
+
+// charMapPageSize is the number of character codes shown per page, one
+// full display line.
+const charMapPageSize = 16
+
+// CharMap pages through all (256) character codes of the display, 16
+// codes at a time, e.g. for mapping the glyph table of a unit. The top
+// line shows the characters and the bottom line shows the code range:
+//
+//	c := lcm.NewCharMap()
+//	for {
+//		top, bottom := c.Page()
+//		m.SendBatch(top, bottom)
+//		// Wait for button press (Up / Down).
+//		if !c.Next() {
+//			break
+//		}
+//	}
+//
+// Navigation is clamped at the first and last page.
+type CharMap struct {
+	page int
+}
+
+// NewCharMap returns a CharMap positioned on the first page.
+func NewCharMap() *CharMap {
+	return &CharMap{}
+}
+
+// Page returns the messages for showing the current page.
+func (c *CharMap) Page() (top, bottom Message) {
+	first, last := c.Range()
+	chars := make([]byte, charMapPageSize)
+	for i := range chars {
+		chars[i] = first + byte(i)
+	}
+	top, _ = SetDisplay(DisplayTop, 0, string(chars))
+	bottom, _ = SetDisplay(DisplayBottom, 0, fmt.Sprintf("%03d..........%03d", first, last))
+	return top, bottom
+}
+
+// Range returns the first and last character code on the current page.
+func (c *CharMap) Range() (first, last byte) {
+	first = byte(c.page * charMapPageSize)
+	return first, first + charMapPageSize - 1
+}
+
+// Next moves to the next page, returns false (and does not move) when
+// already on the last page.
+func (c *CharMap) Next() bool {
+	if c.page >= 256/charMapPageSize-1 {
+		return false
+	}
+	c.page++
+	return true
+}
+
+// Prev moves to the previous page, returns false (and does not move)
+// when already on the first page.
+func (c *CharMap) Prev() bool {
+	if c.page <= 0 {
+		return false
+	}
+	c.page--
+	return true
+}
+
+// First moves to the first page.
+func (c *CharMap) First() {
+	c.page = 0
+}
@@ -0,0 +1,85 @@
+package lcm
+
+import (
+	"testing"
+)
+
+func TestCharMap(t *testing.T) {
+	c := NewCharMap()
+
+	if first, last := c.Range(); first != 0 || last != 15 {
+		t.Errorf("Range() = %d, %d, want 0, 15", first, last)
+	}
+	if c.Prev() {
+		t.Error("Prev() on first page = true, want false")
+	}
+	if first, _ := c.Range(); first != 0 {
+		t.Errorf("Range() after Prev() on first page = %d, want 0", first)
+	}
+
+	for i := 1; i < 16; i++ {
+		if !c.Next() {
+			t.Fatalf("Next() #%d = false, want true", i)
+		}
+	}
+	if first, last := c.Range(); first != 240 || last != 255 {
+		t.Errorf("Range() on last page = %d, %d, want 240, 255", first, last)
+	}
+	if c.Next() {
+		t.Error("Next() on last page = true, want false")
+	}
+	if first, _ := c.Range(); first != 240 {
+		t.Errorf("Range() after Next() on last page = %d, want 240", first)
+	}
+
+	top, bottom := c.Page()
+	for i, b := range top[5:] {
+		if b != byte(240+i) {
+			t.Errorf("Page() top[%d] = %d, want %d", i, b, 240+i)
+		}
+	}
+	if got := string(bottom[5:]); got != "240..........255" {
+		t.Errorf("Page() bottom = %q, want %q", got, "240..........255")
+	}
+
+	if !c.Prev() {
+		t.Error("Prev() on last page = false, want true")
+	}
+	if first, _ := c.Range(); first != 224 {
+		t.Errorf("Range() after Prev() = %d, want 224", first)
+	}
+}
+
+func TestShowAllCharCodes(t *testing.T) {
+	next, goBack := ShowAllCharCodes()
+
+	rangeOf := func(line2 Message) string { return string(line2[5:]) }
+
+	_, line2, start, done := next()
+	if got := rangeOf(line2); got != "000..........015" || !start || done {
+		t.Errorf("next() = %q, %v, %v, want first page", got, start, done)
+	}
+	next()
+	goBack()
+	_, line2, _, _ = next()
+	if got := rangeOf(line2); got != "000..........015" {
+		t.Errorf("next() after goBack() = %q, want first page", got)
+	}
+	goBack()
+	_, line2, _, _ = next()
+	if got := rangeOf(line2); got != "000..........015" {
+		t.Errorf("next() after goBack() on first page = %q, want first page", got)
+	}
+
+	for i := 0; i < 14; i++ {
+		next()
+	}
+	_, line2, start, done = next()
+	if got := rangeOf(line2); got != "240..........255" || start || !done {
+		t.Errorf("next() = %q, %v, %v, want last page", got, start, done)
+	}
+	_, line2, start, _ = next()
+	if got := rangeOf(line2); got != "000..........015" || !start {
+		t.Errorf("next() after last page = %q, %v, want first page", got, start)
+	}
+}
@@ -0,0 +1,106 @@
+package lcm
+
+// CharSet maps the character codes that render as printable glyphs on
+// the display to the rune they represent.
+type CharSet map[byte]rune
+
+// Supports returns the character code for r, if the rune is printable
+// on the display.
+func (cs CharSet) Supports(r rune) (byte, bool) {
+	for c, cr := range cs {
+		if cr == r {
+			return c, true
+		}
+	}
+	return 0, false
+}
+
+// Replace the character codes in text that are not in the character
+// set with fallback, e.g. control characters or codes that have not been
+// mapped yet.
+//
+//	cs.Replace("a\nb", '?') // "a?b"
+func (cs CharSet) Replace(text string, fallback byte) string {
+	b := []byte(text)
+	for i, c := range b {
+		if _, ok := cs[c]; !ok {
+			b[i] = fallback
+		}
+	}
+	return string(b)
+}
+
+// CharSetV012 is the character set of the MCU firmware version 0.1.2
+// (see LCM.Version), the most common version found in the wild.
+//
+// This is a placeholder, not the recorded glyph table of the firmware:
+// it only contains printable ASCII (0x20-0x7E), the same assumption
+// Transliterate makes. Until the table is recorded (see lcm-charmap),
+// Supports reports no codes outside ASCII and Sym uses the ASCII
+// fallback for every symbol.
+var CharSetV012 = asciiCharSet()
+
+// charSets lists the known character sets per MCU version.
+var charSets = map[[3]int]CharSet{
+	{0, 1, 2}: CharSetV012,
+}
+
+// CharSetForVersion returns the character set for the MCU version, if
+// known.
+func CharSetForVersion(major, minor, patch int) (CharSet, bool) {
+	cs, ok := charSets[[3]int{major, minor, patch}]
+	return cs, ok
+}
+
+func asciiCharSet() CharSet {
+	cs := make(CharSet)
+	for c := byte(0x20); c <= 0x7E; c++ {
+		cs[c] = rune(c)
+	}
+	return cs
+}
+
+// Symbols are the character codes for common symbols, see Sym.
+type Symbols struct {
+	ArrowUp    byte
+	ArrowDown  byte
+	ArrowLeft  byte
+	ArrowRight byte
+	Degree     byte
+	Block      byte
+}
+
+// Sym are the symbols for the known firmware (see CharSetV012). Symbols
+// that have no glyph in the character set use an ASCII fallback, e.g.
+// ArrowUp is '^' and Block is '#'. Until CharSetV012 is recorded, all
+// symbols use their fallback.
+var Sym = SymbolsFor(CharSetV012)
+
+// symbolFallbacks maps each symbol to its Unicode rune and ASCII
+// fallback.
+var symbolFallbacks = []struct {
+	sym      func(*Symbols) *byte
+	r        rune
+	fallback byte
+}{
+	{func(s *Symbols) *byte { return &s.ArrowUp }, '↑', '^'},
+	{func(s *Symbols) *byte { return &s.ArrowDown }, '↓', 'v'},
+	{func(s *Symbols) *byte { return &s.ArrowLeft }, '←', '<'},
+	{func(s *Symbols) *byte { return &s.ArrowRight }, '→', '>'},
+	{func(s *Symbols) *byte { return &s.Degree }, '°', 'o'},
+	{func(s *Symbols) *byte { return &s.Block }, '█', '#'},
+}
+
+// SymbolsFor returns the symbols for the character set, using ASCII
+// fallbacks for symbols the character set does not support.
+func SymbolsFor(cs CharSet) Symbols {
+	var s Symbols
+	for _, f := range symbolFallbacks {
+		c, ok := cs.Supports(f.r)
+		if !ok {
+			c = f.fallback
+		}
+		*f.sym(&s) = c
+	}
+	return s
+}
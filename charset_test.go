@@ -0,0 +1,74 @@
+package lcm
+
+import (
+	"testing"
+)
+
+func TestCharSet_Supports(t *testing.T) {
+	cs, ok := CharSetForVersion(0, 1, 2)
+	if !ok {
+		t.Fatal("CharSetForVersion(0, 1, 2) not found")
+	}
+
+	tests := []struct {
+		r      rune
+		want   byte
+		wantOk bool
+	}{
+		{'A', 'A', true},
+		{' ', ' ', true},
+		{'~', '~', true},
+		{'\n', 0, false},
+		{'é', 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := cs.Supports(tt.r)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("Supports(%q) = %d, %v, want %d, %v", tt.r, got, ok, tt.want, tt.wantOk)
+		}
+	}
+
+	if _, ok := CharSetForVersion(9, 9, 9); ok {
+		t.Error("CharSetForVersion(9, 9, 9) found, want not found")
+	}
+}
+
+func TestSymbolsFor(t *testing.T) {
+	want := Symbols{
+		ArrowUp:    '^',
+		ArrowDown:  'v',
+		ArrowLeft:  '<',
+		ArrowRight: '>',
+		Degree:     'o',
+		Block:      '#',
+	}
+	if Sym != want {
+		t.Errorf("Sym = %+v, want %+v", Sym, want)
+	}
+
+	cs := asciiCharSet()
+	cs[0xDF] = '°'
+	cs[0xFF] = '█'
+	want.Degree = 0xDF
+	want.Block = 0xFF
+	if got := SymbolsFor(cs); got != want {
+		t.Errorf("SymbolsFor() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCharSet_Replace(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"hello", "hello"},
+		{"a\nb", "a?b"},
+		{"\x00abc\x00", "?abc?"},
+		{"caf\xc3\xa9", "caf??"},
+	}
+	for _, tt := range tests {
+		if got := CharSetV012.Replace(tt.text, '?'); got != tt.want {
+			t.Errorf("Replace(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
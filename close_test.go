@@ -0,0 +1,43 @@
+package lcm_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mafredri/lcm"
+	"github.com/mafredri/lcm/lcmtest"
+)
+
+func TestLCM_Close(t *testing.T) {
+	m, f := openFake(t, lcmtest.NoReply)
+
+	const n = 5
+	errc := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() { errc <- m.Send(lcm.DisplayOn) }()
+	}
+	// Wait for one send to be in-flight, the others are either
+	// queued or waiting to be.
+	waitReceived(t, f, 1)
+
+	err := m.Close()
+	if err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	for i := 0; i < n; i++ {
+		select {
+		case err := <-errc:
+			if !errors.Is(err, lcm.ErrClosed) {
+				t.Errorf("Send() error = %v, want %v", err, lcm.ErrClosed)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Send() did not return after Close")
+		}
+	}
+
+	if err := m.Send(lcm.DisplayOn); !errors.Is(err, lcm.ErrClosed) {
+		t.Errorf("Send() after Close error = %v, want %v", err, lcm.ErrClosed)
+	}
+}
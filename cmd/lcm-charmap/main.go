@@ -0,0 +1,184 @@
+/*
+lcm-charmap shows all (256) character codes on the display, 16 codes
+at a time, to help build the glyph table for new firmware (see
+lcm.CharSet).
+
+Without -out the pages are cycled with a short delay. With -out the
+command waits for the glyphs seen on the display to be typed in after
+each page, one rune per code (e.g. "°" or "→", use "?" for unknown and
+"_" for blank). An empty line skips the page and "q" stops early. The
+annotations are written as a Markdown table or CSV (-format).
+
+Usage:
+
+	lcm-charmap [-tty /dev/ttyS1] [-delay 2s]
+	lcm-charmap -out charmap.md [-format md|csv]
+*/
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mafredri/lcm"
+)
+
+const pageSize = 16
+
+func main() {
+	tty := flag.String("tty", lcm.DefaultTTY, "LCM serial port")
+	delay := flag.Duration("delay", 2*time.Second, "Delay between pages (without -out)")
+	out := flag.String("out", "", "Record the observed glyphs to this file")
+	format := flag.String("format", "md", "Output format (md or csv)")
+	debug := flag.Bool("debug", false, "Enable debug logging")
+	flag.Parse()
+
+	if err := run(*tty, *delay, *out, *format, *debug); err != nil {
+		fmt.Fprintf(os.Stderr, "lcm-charmap: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(tty string, delay time.Duration, out, format string, debug bool) error {
+	if format != "md" && format != "csv" {
+		return fmt.Errorf("unknown format %q", format)
+	}
+
+	var opts []lcm.OpenOption
+	if debug {
+		opts = append(opts, lcm.WithLogger(log.New(os.Stderr, "[lcm] ", log.Lmicroseconds)), lcm.WithVerbose(true))
+	}
+	m, err := lcm.Open(tty, opts...)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	var glyphs map[byte]string
+	if out != "" {
+		glyphs = make(map[byte]string)
+	}
+	in := bufio.NewScanner(os.Stdin)
+
+	c := lcm.NewCharMap()
+	for {
+		first, last := c.Range()
+		top, bottom := c.Page()
+		if err := m.SendBatch(top, bottom); err != nil {
+			return fmt.Errorf("page %d-%d: %w", first, last, err)
+		}
+
+		if glyphs == nil {
+			fmt.Printf("Showing %03d-%03d\n", first, last)
+			time.Sleep(delay)
+		} else {
+			quit, err := annotate(in, first, glyphs)
+			if err != nil {
+				return err
+			}
+			if quit {
+				break
+			}
+		}
+
+		if !c.Next() {
+			break
+		}
+	}
+
+	if glyphs == nil {
+		return nil
+	}
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	if format == "csv" {
+		err = writeCSV(f, glyphs)
+	} else {
+		err = writeMarkdown(f, glyphs)
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// annotate prompts for the glyphs of the page starting at first until a
+// valid line is entered, returns true when the user wants to quit.
+func annotate(in *bufio.Scanner, first byte, glyphs map[byte]string) (quit bool, err error) {
+	for {
+		fmt.Printf("%03d-%03d glyphs (%d runes, empty to skip, q to quit): ", first, int(first)+pageSize-1, pageSize)
+		if !in.Scan() {
+			if err := in.Err(); err != nil {
+				return false, err
+			}
+			return true, nil // EOF.
+		}
+
+		line := in.Text()
+		switch {
+		case line == "":
+			return false, nil
+		case line == "q":
+			return true, nil
+		case utf8.RuneCountInString(line) != pageSize:
+			fmt.Printf("got %d runes, want %d\n", utf8.RuneCountInString(line), pageSize)
+			continue
+		}
+
+		code := first
+		for _, r := range line {
+			glyphs[code] = string(r)
+			code++
+		}
+		return false, nil
+	}
+}
+
+func writeMarkdown(w io.Writer, glyphs map[byte]string) error {
+	var b strings.Builder
+	b.WriteString("| Code | Hex | Glyph |\n")
+	b.WriteString("| ---: | --- | :---: |\n")
+	for code := 0; code < 256; code++ {
+		g, ok := glyphs[byte(code)]
+		if !ok {
+			continue
+		}
+		if g == "|" {
+			g = `\|`
+		}
+		fmt.Fprintf(&b, "| %d | 0x%02X | %s |\n", code, code, g)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeCSV(w io.Writer, glyphs map[byte]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"code", "hex", "glyph"}); err != nil {
+		return err
+	}
+	for code := 0; code < 256; code++ {
+		g, ok := glyphs[byte(code)]
+		if !ok {
+			continue
+		}
+		err := cw.Write([]string{strconv.Itoa(code), fmt.Sprintf("0x%02X", code), g})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
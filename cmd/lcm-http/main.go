@@ -0,0 +1,233 @@
+/*
+lcm-http exposes the LCM over HTTP, making it scriptable with curl.
+
+Endpoints:
+
+	POST /text           Set text, JSON body: {"line": 0, "indent": 0, "text": "Hello"}
+	POST /display/on     Turn the display on
+	POST /display/off    Turn the display off
+	POST /clear          Clear the display
+	GET  /events         Button presses as server-sent events
+
+For example:
+
+	curl -d '{"line": 1, "text": "Hello"}' http://localhost:8080/text
+	curl -N http://localhost:8080/events
+
+There is no authentication, by default only loopback is listened on.
+*/
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/mafredri/lcm"
+)
+
+func main() {
+	tty := flag.String("tty", lcm.DefaultTTY, "LCM serial port")
+	addr := flag.String("addr", "127.0.0.1:8080", "HTTP listen address")
+	debug := flag.Bool("debug", false, "Enable debug logging")
+	flag.Parse()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	if err := run(ctx, *tty, *addr, *debug); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(ctx context.Context, tty, addr string, debug bool) error {
+	var opts []lcm.OpenOption
+	if debug {
+		opts = append(opts, lcm.WithLogger(log.New(os.Stderr, "[lcm] ", log.Flags())), lcm.WithVerbose(true))
+	}
+	m, err := lcm.Open(tty, opts...)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	s := newServer(m)
+	go s.recv(ctx)
+
+	srv := &http.Server{
+		Addr:    addr,
+		Handler: s.handler(),
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("Listening on %s", addr)
+	err = srv.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+type server struct {
+	m *lcm.LCM
+
+	mu   sync.Mutex
+	subs map[chan lcm.Button]struct{}
+}
+
+func newServer(m *lcm.LCM) *server {
+	return &server{
+		m:    m,
+		subs: make(map[chan lcm.Button]struct{}),
+	}
+}
+
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/text", post(s.text))
+	mux.HandleFunc("/display/on", post(s.send(lcm.DisplayOn)))
+	mux.HandleFunc("/display/off", post(s.send(lcm.DisplayOff)))
+	mux.HandleFunc("/clear", post(s.send(lcm.ClearDisplay)))
+	mux.HandleFunc("/events", s.events)
+	return mux
+}
+
+// post only allows the POST method for the handler.
+func post(fn http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		fn(w, r)
+	}
+}
+
+type textRequest struct {
+	Line   lcm.DisplayLine `json:"line"`
+	Indent int             `json:"indent"`
+	Text   string          `json:"text"`
+}
+
+func (s *server) text(w http.ResponseWriter, r *http.Request) {
+	var req textRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1024))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&req)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+	if req.Indent < 0 {
+		http.Error(w, "indentation out of bounds, [0, 15]", http.StatusBadRequest)
+		return
+	}
+
+	// SetDisplayText validates the line, indent and text length.
+	msg, err := lcm.SetDisplayText(req.Line, req.Indent, req.Text)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.send(msg)(w, r)
+}
+
+func (s *server) send(msg lcm.Message) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := s.m.Send(msg)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// events streams button presses as server-sent events, all clients
+// receive all button presses.
+func (s *server) events(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	c := s.subscribe()
+	defer s.unsubscribe(c)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case btn := <-c:
+			_, err := fmt.Fprintf(w, "event: button\ndata: %s\n\n", btn)
+			if err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func (s *server) subscribe() chan lcm.Button {
+	c := make(chan lcm.Button, 5)
+	s.mu.Lock()
+	s.subs[c] = struct{}{}
+	s.mu.Unlock()
+	return c
+}
+
+func (s *server) unsubscribe(c chan lcm.Button) {
+	s.mu.Lock()
+	delete(s.subs, c)
+	s.mu.Unlock()
+}
+
+// recv messages from the display and broadcast button presses to the
+// subscribers, slow subscribers miss button presses.
+func (s *server) recv(ctx context.Context) {
+	for {
+		msg, err := s.m.RecvContext(ctx)
+		if err != nil {
+			return
+		}
+		d, err := msg.Decode()
+		if err != nil || d.Type != lcm.Command || d.Function != lcm.Fbutton {
+			continue
+		}
+
+		s.mu.Lock()
+		for c := range s.subs {
+			select {
+			case c <- d.Button:
+			default:
+			}
+		}
+		s.mu.Unlock()
+	}
+}
@@ -6,8 +6,13 @@ files.
 
 The socat unix command must be installed on the target system.
 
+By default the messages are decoded and written as timestamped lines,
+e.g. "15:04:05.123 OUT Command len=18 Ftext data=[...] "Hello"". Use -raw
+to write the raw bytes instead (e.g. for use with lcm-replay).
+
 Usage:
 	lcm-monitor -out output.txt
+	lcm-monitor -raw -out capture.bin
 
 */
 package main
@@ -22,8 +27,11 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"time"
 
 	"github.com/pkg/term"
+
+	"github.com/mafredri/lcm"
 )
 
 const (
@@ -35,14 +43,15 @@ func main() {
 	baud := flag.Int("baud", 115200, "baud rate")
 	out := flag.String("out", "", "output file")
 	socat := flag.String("socat", "/usr/bin/socat", "socat binary")
+	raw := flag.Bool("raw", false, "write raw bytes instead of decoded messages")
 	flag.Parse()
 
-	if err := run(*baud, *out, *socat); err != nil {
+	if err := run(*baud, *out, *socat, *raw); err != nil {
 		panic(err)
 	}
 }
 
-func run(baud int, outfile, socatBin string) error {
+func run(baud int, outfile, socatBin string, raw bool) error {
 	if outfile == "" {
 		return errors.New("out must be set")
 	}
@@ -81,18 +90,23 @@ func run(baud int, outfile, socatBin string) error {
 	}
 	defer out.Close()
 
+	teeFn := decode
+	if raw {
+		teeFn = tee
+	}
+
 	errc := make(chan error, 1)
-	go func() { errc <- tee(s, stdin, " IN", out) }()
-	go func() { errc <- tee(stdout, s, "OUT", out) }()
+	go func() { errc <- teeFn(s, stdin, " IN", out) }()
+	go func() { errc <- teeFn(stdout, s, "OUT", out) }()
 	go func() { errc <- socat.Wait() }()
 
 	return <-errc
 }
 
+// tee writes the raw bytes read from r to out.
 func tee(r io.Reader, w io.Writer, id string, out io.Writer) error {
 	var buf bytes.Buffer
 	rr := bufio.NewReader(io.TeeReader(r, w))
-	// t := time.Now()
 	for {
 		b, err := rr.ReadByte()
 		if err != nil {
@@ -101,23 +115,43 @@ func tee(r io.Reader, w io.Writer, id string, out io.Writer) error {
 			}
 			return err
 		}
-		// if b == 0x4D || b == 0x53 {
-		// 	if buf.Len() > 0 {
-		// 		s := hex.EncodeToString(buf.Bytes())
-		// 		ts := t.Format("15:04:05.999999999")
-		// 		for len(ts) < 18 {
-		// 			ts += "0"
-		// 		}
-		// 		_, err = fmt.Fprintf(out, "%s[%s]: %s (%s)\n", ts, id, s, buf.String())
-		// 		if err != nil {
-		// 			return err
-		// 		}
-		// 		buf.Reset()
-		// 	}
-		// 	t = time.Now()
-		// }
 		buf.WriteByte(b)
 		buf.WriteTo(out)
 		buf.Reset()
 	}
 }
+
+// decode the messages read from r and write them to out, one per line,
+// timestamped and tagged with the direction (id).
+func decode(r io.Reader, w io.Writer, id string, out io.Writer) error {
+	mr := lcm.NewMessageReader(io.TeeReader(r, w))
+	for {
+		msg, err := mr.ReadMessage()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		ts := time.Now().Format("15:04:05.000")
+		_, err = fmt.Fprintf(out, "%s %s %v%s\n", ts, id, msg, annotate(msg))
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// annotate returns a human readable annotation for known messages.
+func annotate(msg lcm.Message) string {
+	d, err := msg.Decode()
+	if err != nil {
+		return ""
+	}
+	switch {
+	case d.Type == lcm.Command && d.Function == lcm.Ftext && len(d.Data) > 2:
+		return fmt.Sprintf(" %q", d.Data[2:])
+	case d.Type == lcm.Command && d.Function == lcm.Fbutton && len(d.Data) == 1:
+		return fmt.Sprintf(" (%v)", d.Button)
+	}
+	return ""
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// Minimal MQTT 3.1.1 client, only QoS 0 is supported which is enough
+// for button events and display updates.
+
+// MQTT control packet types.
+const (
+	mqttConnect    = 1
+	mqttConnack    = 2
+	mqttPublish    = 3
+	mqttSubscribe  = 8
+	mqttSuback     = 9
+	mqttPingreq    = 12
+	mqttPingresp   = 13
+	mqttDisconnect = 14
+)
+
+// mqttMaxPacketSize limits the size of packets read from the broker,
+// the remaining length allows up to 256MB. Button events and display
+// text are far smaller.
+const mqttMaxPacketSize = 64 * 1024
+
+type mqttClient struct {
+	conn      net.Conn
+	r         *bufio.Reader
+	keepAlive time.Duration
+
+	mu     sync.Mutex // Protects writes.
+	nextID uint16
+}
+
+// dialMQTT connects to the broker at addr (host:port).
+func dialMQTT(ctx context.Context, addr, clientID, username, password string, keepAlive time.Duration) (*mqttClient, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	c := &mqttClient{
+		conn:      conn,
+		r:         bufio.NewReader(conn),
+		keepAlive: keepAlive,
+	}
+
+	flags := byte(0x02) // Clean session.
+	if username != "" {
+		flags |= 0x80
+	}
+	if password != "" {
+		flags |= 0x40
+	}
+	var b []byte
+	b = appendString(b, "MQTT")
+	b = append(b, 4, flags) // Protocol level 3.1.1.
+	b = appendUint16(b, uint16(keepAlive/time.Second))
+	b = appendString(b, clientID)
+	if username != "" {
+		b = appendString(b, username)
+	}
+	if password != "" {
+		b = appendString(b, password)
+	}
+
+	err = c.write(mqttConnect<<4, b)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	typ, body, err := c.read()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("connack: %w", err)
+	}
+	if typ != mqttConnack || len(body) != 2 {
+		conn.Close()
+		return nil, fmt.Errorf("connack: unexpected packet type %d", typ)
+	}
+	if body[1] != 0 {
+		conn.Close()
+		return nil, fmt.Errorf("connack: connection refused (%d)", body[1])
+	}
+	return c, nil
+}
+
+// publish the payload to topic with QoS 0.
+func (c *mqttClient) publish(topic string, payload []byte, retain bool) error {
+	var flags byte
+	if retain {
+		flags = 0x01
+	}
+	b := appendString(nil, topic)
+	b = append(b, payload...)
+	return c.write(mqttPublish<<4|flags, b)
+}
+
+// subscribe to the topics with QoS 0, the SUBACK is handled by serve.
+func (c *mqttClient) subscribe(topics ...string) error {
+	c.mu.Lock()
+	c.nextID++
+	id := c.nextID
+	c.mu.Unlock()
+
+	b := appendUint16(nil, id)
+	for _, t := range topics {
+		b = appendString(b, t)
+		b = append(b, 0) // QoS 0.
+	}
+	return c.write(mqttSubscribe<<4|0x02, b)
+}
+
+// serve reads packets from the broker and calls fn for each published
+// message, it also keeps the connection alive. It returns when the
+// connection is lost or the context is canceled.
+func (c *mqttClient) serve(ctx context.Context, fn func(topic string, payload []byte)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		t := time.NewTicker(c.keepAlive)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				c.conn.Close() // Unblock read.
+				return
+			case <-t.C:
+				_ = c.write(mqttPingreq<<4, nil)
+			}
+		}
+	}()
+
+	for {
+		// The broker responds to our pings, allow some slack
+		// before considering the connection lost.
+		_ = c.conn.SetReadDeadline(time.Now().Add(c.keepAlive * 3 / 2))
+		typ, body, err := c.read()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		switch typ {
+		case mqttPublish:
+			topic, payload, err := parsePublish(body)
+			if err != nil {
+				return err
+			}
+			fn(topic, payload)
+		case mqttSuback:
+			if len(body) < 3 {
+				return errors.New("suback: packet too short")
+			}
+			for _, rc := range body[2:] {
+				if rc == 0x80 {
+					return errors.New("subscribe: refused by broker")
+				}
+			}
+		case mqttPingresp:
+		default:
+			return fmt.Errorf("unexpected packet type %d", typ)
+		}
+	}
+}
+
+// Close disconnects from the broker.
+func (c *mqttClient) Close() error {
+	_ = c.write(mqttDisconnect<<4, nil)
+	return c.conn.Close()
+}
+
+func (c *mqttClient) write(header byte, body []byte) error {
+	b := []byte{header}
+	b = appendLength(b, len(body))
+	b = append(b, body...)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_ = c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	_, err := c.conn.Write(b)
+	return err
+}
+
+func (c *mqttClient) read() (typ byte, body []byte, err error) {
+	header, err := c.r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	n, err := readLength(c.r)
+	if err != nil {
+		return 0, nil, err
+	}
+	if n > mqttMaxPacketSize {
+		return 0, nil, fmt.Errorf("packet too large (%d bytes)", n)
+	}
+	body = make([]byte, n)
+	_, err = io.ReadFull(c.r, body)
+	if err != nil {
+		return 0, nil, err
+	}
+	return header >> 4, body, nil
+}
+
+// parsePublish parses a QoS 0 PUBLISH packet body.
+func parsePublish(b []byte) (topic string, payload []byte, err error) {
+	if len(b) < 2 {
+		return "", nil, errors.New("publish: packet too short")
+	}
+	n := int(binary.BigEndian.Uint16(b))
+	if len(b) < 2+n {
+		return "", nil, errors.New("publish: topic too long")
+	}
+	return string(b[2 : 2+n]), b[2+n:], nil
+}
+
+func appendString(b []byte, s string) []byte {
+	b = appendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// appendLength appends the MQTT variable length encoding of n.
+func appendLength(b []byte, n int) []byte {
+	for {
+		c := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			c |= 0x80
+		}
+		b = append(b, c)
+		if n == 0 {
+			return b
+		}
+	}
+}
+
+func readLength(r io.ByteReader) (int, error) {
+	var n, shift int
+	for i := 0; i < 4; i++ {
+		c, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		n |= int(c&0x7f) << shift
+		if c&0x80 == 0 {
+			return n, nil
+		}
+		shift += 7
+	}
+	return 0, errors.New("malformed remaining length")
+}
+
+func appendUint16(b []byte, v uint16) []byte {
+	return append(b, byte(v>>8), byte(v))
+}
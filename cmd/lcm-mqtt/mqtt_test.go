@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestLength(t *testing.T) {
+	tests := []struct {
+		n    int
+		want []byte
+	}{
+		{0, []byte{0x00}},
+		{127, []byte{0x7f}},
+		{128, []byte{0x80, 0x01}},
+		{16383, []byte{0xff, 0x7f}},
+		{16384, []byte{0x80, 0x80, 0x01}},
+		{2097151, []byte{0xff, 0xff, 0x7f}},
+		{2097152, []byte{0x80, 0x80, 0x80, 0x01}},
+		{268435455, []byte{0xff, 0xff, 0xff, 0x7f}},
+	}
+	for _, tt := range tests {
+		got := appendLength(nil, tt.n)
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("appendLength(%d) = %#x, want %#x", tt.n, got, tt.want)
+		}
+		n, err := readLength(bytes.NewReader(got))
+		if err != nil || n != tt.n {
+			t.Errorf("readLength(%#x) = %d, %v, want %d", got, n, err, tt.n)
+		}
+	}
+
+	if _, err := readLength(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff, 0x7f})); err == nil {
+		t.Error("readLength(5 bytes) error = nil, want malformed")
+	}
+	if _, err := readLength(bytes.NewReader([]byte{0x80})); err != io.EOF {
+		t.Errorf("readLength(truncated) error = %v, want %v", err, io.EOF)
+	}
+}
+
+func TestParsePublish(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        []byte
+		wantTopic   string
+		wantPayload string
+		wantErr     bool
+	}{
+		{"Publish", append(appendString(nil, "lcm/top"), "Hello"...), "lcm/top", "Hello", false},
+		{"Empty payload", appendString(nil, "lcm/top"), "lcm/top", "", false},
+		{"Too short", []byte{0x00}, "", "", true},
+		{"Topic too long", []byte{0x00, 0x05, 'l', 'c', 'm'}, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topic, payload, err := parsePublish(tt.body)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePublish() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if topic != tt.wantTopic || string(payload) != tt.wantPayload {
+				t.Errorf("parsePublish() = %q, %q, want %q, %q", topic, payload, tt.wantTopic, tt.wantPayload)
+			}
+		})
+	}
+}
+
+func TestMQTTClient_read(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		wantType byte
+		wantBody []byte
+		wantErr  bool
+	}{
+		{"Pingresp", []byte{mqttPingresp << 4, 0x00}, mqttPingresp, []byte{}, false},
+		{"Connack", []byte{mqttConnack << 4, 0x02, 0x00, 0x00}, mqttConnack, []byte{0x00, 0x00}, false},
+		{"Truncated", []byte{mqttConnack << 4, 0x02, 0x00}, 0, nil, true},
+		{"Too large", append(append([]byte{mqttPublish << 4}, appendLength(nil, mqttMaxPacketSize+1)...), make([]byte, mqttMaxPacketSize+1)...), 0, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &mqttClient{r: bufio.NewReader(bytes.NewReader(tt.data))}
+			typ, body, err := c.read()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("read() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if typ != tt.wantType || !bytes.Equal(body, tt.wantBody) {
+				t.Errorf("read() = %d, %#x, want %d, %#x", typ, body, tt.wantType, tt.wantBody)
+			}
+		})
+	}
+}
+
+// readPacket reads a packet on the broker side.
+func readPacket(t *testing.T, r *bufio.Reader) (header byte, body []byte) {
+	t.Helper()
+	header, err := r.ReadByte()
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := readLength(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body = make([]byte, n)
+	if _, err := io.ReadFull(r, body); err != nil {
+		t.Fatal(err)
+	}
+	return header, body
+}
+
+func TestMQTTClient(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	type result struct {
+		c   *mqttClient
+		err error
+	}
+	dialed := make(chan result, 1)
+	go func() {
+		c, err := dialMQTT(ctx, ln.Addr().String(), "lcm", "user", "pass", time.Minute)
+		dialed <- result{c, err}
+	}()
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	header, body := readPacket(t, r)
+	var want []byte
+	want = appendString(want, "MQTT")
+	want = append(want, 4, 0xc2)
+	want = appendUint16(want, 60)
+	want = appendString(want, "lcm")
+	want = appendString(want, "user")
+	want = appendString(want, "pass")
+	if header != mqttConnect<<4 || !bytes.Equal(body, want) {
+		t.Fatalf("connect = %#x %#x, want %#x %#x", header, body, mqttConnect<<4, want)
+	}
+	if _, err := conn.Write([]byte{mqttConnack << 4, 0x02, 0x00, 0x00}); err != nil {
+		t.Fatal(err)
+	}
+
+	res := <-dialed
+	if res.err != nil {
+		t.Fatalf("dialMQTT() error = %v", res.err)
+	}
+	c := res.c
+	defer c.Close()
+
+	if err := c.subscribe("lcm/top"); err != nil {
+		t.Fatal(err)
+	}
+	header, body = readPacket(t, r)
+	want = append(appendString(appendUint16(nil, 1), "lcm/top"), 0)
+	if header != mqttSubscribe<<4|0x02 || !bytes.Equal(body, want) {
+		t.Errorf("subscribe = %#x %#x, want %#x %#x", header, body, mqttSubscribe<<4|0x02, want)
+	}
+
+	if err := c.publish("lcm/button", []byte("Enter"), true); err != nil {
+		t.Fatal(err)
+	}
+	header, body = readPacket(t, r)
+	want = append(appendString(nil, "lcm/button"), "Enter"...)
+	if header != mqttPublish<<4|0x01 || !bytes.Equal(body, want) {
+		t.Errorf("publish = %#x %#x, want %#x %#x", header, body, mqttPublish<<4|0x01, want)
+	}
+
+	// The broker acknowledges the subscription and publishes a
+	// message, then closes the connection.
+	var out []byte
+	out = append(out, mqttSuback<<4, 0x03, 0x00, 0x01, 0x00)
+	pub := append(appendString(nil, "lcm/top"), "Hello"...)
+	out = append(append(append(out, mqttPublish<<4), appendLength(nil, len(pub))...), pub...)
+	if _, err := conn.Write(out); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	err = c.serve(ctx, func(topic string, payload []byte) {
+		got = append(got, fmt.Sprintf("%s=%s", topic, payload))
+		conn.Close()
+	})
+	if err == nil {
+		t.Error("serve() error = nil, want connection closed")
+	}
+	if fmt.Sprint(got) != "[lcm/top=Hello]" {
+		t.Errorf("serve() messages = %v, want [lcm/top=Hello]", got)
+	}
+}
@@ -0,0 +1,127 @@
+/*
+lcm-replay replays serial traffic captured by lcm-monitor -raw against a
+fake display (see lcmtest.FakeMCU), useful for reproducing issues offline.
+
+Commands sent by the display (button presses and version) are pushed to
+the host, commands sent by the host are resent via (*lcm.LCM).Send and
+the display replies as captured (OK when the capture has no reply).
+
+Usage:
+
+	lcm-replay [-debug] capture.txt
+*/
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/mafredri/lcm"
+	"github.com/mafredri/lcm/lcmtest"
+)
+
+func main() {
+	debug := flag.Bool("debug", false, "Enable debug logging")
+	flag.Parse()
+
+	if err := run(flag.Arg(0), *debug); err != nil {
+		fmt.Fprintf(os.Stderr, "lcm-replay: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(capture string, debug bool) error {
+	if capture == "" {
+		return errors.New("capture file must be given")
+	}
+
+	msgs, err := lcm.ReplayFile(capture)
+	if err != nil {
+		return err
+	}
+
+	// Captured replies from the display, by function.
+	replies := make(map[lcm.Function][]lcm.Message)
+	for _, msg := range msgs {
+		if msg.Type() == lcm.Reply && !fromHost(msg) {
+			replies[msg.Function()] = append(replies[msg.Function()], msg)
+		}
+	}
+
+	f := lcmtest.NewFakeMCU()
+	f.SetReplyFunc(func(msg lcm.Message) []byte {
+		q := replies[msg.Function()]
+		if len(q) == 0 {
+			return lcmtest.ReplyOk(msg)
+		}
+		replies[msg.Function()] = q[1:]
+		return append(append([]byte(nil), q[0]...), lcm.Checksum(q[0]))
+	})
+
+	var opts []lcm.OpenOption
+	if debug {
+		opts = append(opts, lcm.WithLogger(log.New(os.Stderr, "[lcm] ", log.Lmicroseconds)), lcm.WithVerbose(true))
+	}
+	m := lcm.OpenConn(f, opts...)
+	defer m.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	in := make(chan lcm.Message)
+	go func() {
+		for {
+			msg, err := m.RecvContext(ctx)
+			if err != nil {
+				return
+			}
+			select {
+			case in <- msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for _, msg := range msgs {
+		switch {
+		case msg.Type() == lcm.Reply:
+			// Replies are handled by the fake display.
+
+		case fromDisplay(msg):
+			f.Push(msg)
+			select {
+			case got := <-in:
+				fmt.Printf(" IN %v\n", got)
+			case <-time.After(time.Second):
+				fmt.Printf(" IN %v: not received\n", msg)
+			}
+
+		default:
+			err := m.Send(msg)
+			if err != nil {
+				fmt.Printf("OUT %v: %v\n", msg, err)
+			} else {
+				fmt.Printf("OUT %v\n", msg)
+			}
+		}
+	}
+
+	return nil
+}
+
+// fromDisplay reports if the command was sent by the display.
+func fromDisplay(msg lcm.Message) bool {
+	return msg.Type() == lcm.Command && (msg.Function() == lcm.Fbutton || (msg.Function() == lcm.Fversion && len(msg.Value()) == 3))
+}
+
+// fromHost reports if the reply was sent by the host (acknowledging a
+// button press).
+func fromHost(msg lcm.Message) bool {
+	return msg.Type() == lcm.Reply && msg.Function() == lcm.Fbutton
+}
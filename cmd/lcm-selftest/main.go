@@ -0,0 +1,157 @@
+/*
+lcm-selftest checks which operations the display acknowledges, e.g. to
+find out if an unknown LCD speaks the same protocol. The display is
+turned on, the character table is shown briefly, a test pattern is
+written to both lines and the MCU version is requested. The report can
+be pasted into an issue.
+
+Usage:
+
+	lcm-selftest [-tty /dev/ttyS1] [-debug]
+*/
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/mafredri/lcm"
+)
+
+const (
+	program = "lcm-selftest"
+
+	pageDelay      = 200 * time.Millisecond
+	patternDelay   = 2 * time.Second
+	versionTimeout = 2 * time.Second
+)
+
+func main() {
+	tty := flag.String("tty", lcm.DefaultTTY, "LCM serial port")
+	debug := flag.Bool("debug", false, "Enable debug logging")
+	flag.Parse()
+
+	var opts []lcm.OpenOption
+	if *debug {
+		opts = append(opts, lcm.WithLogger(log.New(os.Stderr, "[lcm] ", log.Lmicroseconds)), lcm.WithVerbose(true))
+	}
+	m, err := lcm.Open(*tty, opts...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", program, err)
+		os.Exit(1)
+	}
+	defer m.Close()
+
+	fmt.Printf("%s (%s/%s, tty %s)\n\n", program, runtime.GOOS, runtime.GOARCH, *tty)
+	if !run(os.Stdout, m) {
+		m.Close()
+		os.Exit(1)
+	}
+}
+
+// check is a single operation of the self-test.
+type check struct {
+	name string
+	fn   func(m *lcm.LCM) (info string, err error)
+}
+
+var checks = []check{
+	{"display on", sendCheck(lcm.DisplayOn)},
+	{"display status", sendCheck(lcm.DisplayStatus)},
+	{"clear display", sendCheck(lcm.ClearDisplay)},
+	{"character table", charTable},
+	{"text pattern", textPattern},
+	{"set character", setCharacter},
+	{"version", version},
+}
+
+// run the checks and write the report to w, returns true if all checks
+// passed.
+func run(w io.Writer, m *lcm.LCM) bool {
+	passed := 0
+	for _, c := range checks {
+		info, err := c.fn(m)
+		switch {
+		case err != nil:
+			fmt.Fprintf(w, "FAIL  %s: %v\n", c.name, err)
+		case info != "":
+			passed++
+			fmt.Fprintf(w, "PASS  %s: %s\n", c.name, info)
+		default:
+			passed++
+			fmt.Fprintf(w, "PASS  %s\n", c.name)
+		}
+	}
+	fmt.Fprintf(w, "\n%d/%d checks passed\n", passed, len(checks))
+	return passed == len(checks)
+}
+
+func sendCheck(msg lcm.Message) func(m *lcm.LCM) (string, error) {
+	return func(m *lcm.LCM) (string, error) {
+		return "", m.Send(msg)
+	}
+}
+
+// charTable shows every page of the character table briefly.
+func charTable(m *lcm.LCM) (string, error) {
+	c := lcm.NewCharMap()
+	pages := 0
+	for {
+		top, bottom := c.Page()
+		if err := m.SendBatch(top, bottom); err != nil {
+			first, _ := c.Range()
+			return "", fmt.Errorf("page %d (code %d): %w", pages+1, first, err)
+		}
+		pages++
+		time.Sleep(pageDelay)
+		if !c.Next() {
+			break
+		}
+	}
+	return fmt.Sprintf("%d pages", pages), nil
+}
+
+// textPattern writes a known pattern to both lines.
+func textPattern(m *lcm.LCM) (string, error) {
+	top, bottom := "0123456789ABCDEF", "abcdefghijklmnop"
+	if err := m.SetLines(top, bottom); err != nil {
+		return "", err
+	}
+	time.Sleep(patternDelay)
+	return fmt.Sprintf("%q / %q", top, bottom), nil
+}
+
+// setCharacter writes a single character on each line.
+func setCharacter(m *lcm.LCM) (string, error) {
+	for _, line := range []lcm.DisplayLine{lcm.DisplayTop, lcm.DisplayBottom} {
+		b, err := lcm.SetDisplayCharacter(line, 15, '*')
+		if err != nil {
+			return "", err
+		}
+		if err := m.Send(b); err != nil {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+func version(m *lcm.LCM) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), versionTimeout)
+	defer cancel()
+
+	major, minor, patch, err := m.Version(ctx)
+	if err != nil {
+		return "", err
+	}
+	info := fmt.Sprintf("%d.%d.%d", major, minor, patch)
+	if _, ok := lcm.CharSetForVersion(major, minor, patch); !ok {
+		info += " (unknown version, please report)"
+	}
+	return info, nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/mafredri/lcm"
+)
+
+const displayWidth = 16
+
+// display emulates the state of the 16x2 display as driven by the
+// commands written by the host.
+type display struct {
+	mu      sync.Mutex
+	on      bool
+	lines   [2][displayWidth]byte
+	changed chan struct{}
+}
+
+func newDisplay() *display {
+	d := &display{changed: make(chan struct{}, 1)}
+	d.clear()
+	return d
+}
+
+func (d *display) clear() {
+	for i := range d.lines {
+		copy(d.lines[i][:], bytes.Repeat([]byte{' '}, displayWidth))
+	}
+}
+
+// apply the command (without checksum) to the display state.
+func (d *display) apply(msg lcm.Message) {
+	if msg.Check() != nil || msg.Type() != lcm.Command {
+		return
+	}
+	v := msg.Value()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	switch msg.Function() {
+	case lcm.Fon:
+		d.on = v[0] != 0
+	case lcm.Fclear, lcm.Fclear2:
+		d.clear()
+	case lcm.Ftext:
+		if len(v) < 2 || v[0] > 1 {
+			return
+		}
+		// Text that is indented past the edge is not visible.
+		line, indent := v[0], int(v[1])
+		if indent < displayWidth {
+			copy(d.lines[line][indent:], v[2:])
+		}
+	case lcm.Fchar:
+		if len(v) != 3 || v[0] > 1 || v[1] >= displayWidth {
+			return
+		}
+		d.lines[v[0]][v[1]] = v[2]
+	default:
+		return
+	}
+
+	select {
+	case d.changed <- struct{}{}:
+	default:
+	}
+}
+
+// render the display to w, characters that are not in the character
+// set are shown as '?'.
+func (d *display) render(w io.Writer, tty string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString("\x1b[H\x1b[2J") // Move home and clear screen.
+	border := "+" + strings.Repeat("-", displayWidth) + "+\r\n"
+	b.WriteString(border)
+	for _, line := range d.lines {
+		b.WriteByte('|')
+		for _, c := range line {
+			r, ok := lcm.CharSetV012[c]
+			switch {
+			case !d.on:
+				r = ' '
+			case !ok:
+				r = '?'
+			}
+			b.WriteRune(r)
+		}
+		b.WriteString("|\r\n")
+	}
+	b.WriteString(border)
+
+	state := "on"
+	if !d.on {
+		state = "off"
+	}
+	fmt.Fprintf(&b, "\r\nDisplay: %s, tty: %s\r\n", state, tty)
+	b.WriteString("Keys: up/down, left (back), right or return (enter), q to quit\r\n")
+
+	io.WriteString(w, b.String())
+}
@@ -0,0 +1,142 @@
+/*
+lcm-sim simulates the display in the terminal, for developing and
+testing menus and layouts without the hardware.
+
+The simulated display (see lcmtest.FakeMCU) is exposed on a
+pseudo-terminal that any program using lcm.Open can connect to, e.g.:
+
+	lcm-sim -link /tmp/lcm
+	openlcmd -tty /tmp/lcm -menu menu.yaml
+
+The arrow keys are sent as button presses: up and down as Up and Down,
+left as Back and right (or return) as Enter.
+
+Usage:
+
+	lcm-sim [-link path]
+*/
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/term"
+	"github.com/pkg/term/termios"
+
+	"github.com/mafredri/lcm"
+	"github.com/mafredri/lcm/lcmtest"
+)
+
+func main() {
+	link := flag.String("link", "", "Create a symlink to the pseudo-terminal at this path")
+	flag.Parse()
+
+	if err := run(*link); err != nil {
+		fmt.Fprintf(os.Stderr, "lcm-sim: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(link string) error {
+	ptm, pts, err := termios.Pty()
+	if err != nil {
+		return err
+	}
+	defer ptm.Close()
+
+	// Keep the host side open in raw mode so that nothing is echoed
+	// back when no program is connected.
+	tty := pts.Name()
+	pts.Close()
+	s, err := term.Open(tty, term.RawMode)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	if link != "" {
+		os.Remove(link)
+		if err := os.Symlink(tty, link); err != nil {
+			return err
+		}
+		defer os.Remove(link)
+		tty = link
+	}
+
+	kbd, err := term.Open("/dev/tty", term.RawMode)
+	if err != nil {
+		return err
+	}
+	defer kbd.Close()
+	defer kbd.Restore()
+
+	d := newDisplay()
+	version := lcmtest.ReplyVersion(0, 1, 2)
+	f := lcmtest.NewFakeMCU()
+	f.SetReplyFunc(func(msg lcm.Message) []byte {
+		d.apply(msg)
+		return version(msg)
+	})
+	defer f.Close()
+
+	go io.Copy(ptm, f)
+	go io.Copy(f, ptm)
+
+	keyC := make(chan []byte)
+	errC := make(chan error, 1)
+	go func() {
+		for {
+			b := make([]byte, 8)
+			n, err := kbd.Read(b)
+			if err != nil {
+				errC <- err
+				return
+			}
+			keyC <- b[:n]
+		}
+	}()
+
+	d.render(os.Stdout, tty)
+	for {
+		select {
+		case <-d.changed:
+			d.render(os.Stdout, tty)
+		case key := <-keyC:
+			if quit(key) {
+				return nil
+			}
+			if b, ok := keyButton(key); ok {
+				f.PushButton(b)
+			}
+		case err := <-errC:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// keyButton maps the key (as read from the terminal) to a button.
+func keyButton(key []byte) (lcm.Button, bool) {
+	switch string(key) {
+	case "\x1b[A":
+		return lcm.Up, true
+	case "\x1b[B":
+		return lcm.Down, true
+	case "\x1b[D":
+		return lcm.Back, true
+	case "\x1b[C", "\r":
+		return lcm.Enter, true
+	}
+	return 0, false
+}
+
+// quit reports if the key is q or ctrl-c.
+func quit(key []byte) bool {
+	return string(key) == "q" || string(key) == "\x03"
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"log"
 	"os"
@@ -11,7 +12,6 @@ import (
 	"time"
 
 	"github.com/bendahl/uinput"
-	"github.com/shirou/gopsutil/v3/net"
 
 	"github.com/mafredri/lcm"
 	"github.com/mafredri/lcm/cmd/openlcmd/monitor"
@@ -25,7 +25,17 @@ const (
 func main() {
 	// TODO(): Configuration.
 	debug := flag.Bool("debug", false, "Enable debug logging")
-	enableSystemd := flag.Bool("systemd", false, "Runs in systemd mode (removes timestamps from logging)")
+	tty := flag.String("tty", lcm.DefaultTTY, "LCM serial port")
+	enableSystemd := flag.Bool("systemd", false, "Runs in systemd mode (removes timestamps from logging, enables sd_notify readiness and watchdog)")
+	menuFile := flag.String("menu", "", "Menu configuration file (YAML), see menuconfig.go")
+	menuPosition := flag.Bool("menu-position", false, "Show the position (e.g. 2/5) in the menu, long item names are truncated instead of scrolled")
+	clock := flag.Bool("clock", false, "Show the current date and time on the home display")
+	clockDate := flag.String("clock-date", monitor.DefaultClockDateLayout, "Date layout for the clock (see time.Layout)")
+	clockTime := flag.String("clock-time", monitor.DefaultClockTimeLayout, "Time layout for the clock (see time.Layout)")
+	idleClock := flag.Duration("idle-clock", 0, "Show the clock on the home display after this long without button presses (0 disables, should be shorter than -idle-timeout)")
+	disks := flag.String("disks", "", "Comma separated list of mountpoints to show disk usage for (e.g. /volume1,/volume2)")
+	splash := flag.Bool("splash", true, "Show a splash animation on startup")
+	idleTimeout := flag.Duration("idle-timeout", monitor.DefaultIdleTimeout, "Turn the display off after this long without activity (0 keeps it always on)")
 	enableUinput := flag.Bool("uinput", false, "Relay button presses via uinput virtual keyboard (/devices/virtual/input)")
 
 	flag.Parse()
@@ -42,17 +52,31 @@ func main() {
 	}
 	log.SetFlags(flags)
 
-	var opts []lcm.OpenOption
-	if *debug {
-		opts = append(opts, lcm.WithLogger(log.New(os.Stderr, "[lcm] ", flags)))
+	opts := []lcm.OpenOption{
+		// Info screens are redrawn periodically, skip
+		// updates that don't change the display.
+		lcm.WithCoalescing(0),
+		// Some units send a button command twice for a single
+		// press, which would navigate the menu twice.
+		lcm.WithButtonDebounce(lcm.DefaultButtonDebounce),
+		// Retries, timeouts and errors are always logged.
+		lcm.WithLogger(log.New(os.Stderr, "[lcm] ", flags)),
+		lcm.WithVerbose(*debug),
 	}
 
-	m, err := lcm.Open(lcm.DefaultTTY, opts...)
+	m, err := lcm.Open(*tty, opts...)
 	if err != nil {
 		panic(err)
 	}
 	defer m.Close()
 
+	if *splash {
+		err = m.Play(ctx, lcm.SplashSequence(program))
+		if err != nil {
+			log.Printf("splash failed: %v", err)
+		}
+	}
+
 	var kbd uinput.Keyboard
 	if *enableUinput {
 		kbd, err = uinput.CreateKeyboard("/dev/uinput", []byte(program))
@@ -62,95 +86,116 @@ func main() {
 		defer kbd.Close()
 	}
 
-	mon := monitor.New(ctx, program, m, kbd)
+	mon := monitor.New(ctx, program, m, kbd, monitor.WithIdleTimeout(*idleTimeout))
 	defer mon.Close()
 
-	mon.SetHome(func(ctx context.Context) error {
+	mon.SetHome(mon.TextScreen(func(ctx context.Context) (top, bottom string, err error) {
 		hostname, err := os.Hostname()
 		if err != nil {
 			hostname = "Unknown"
 			log.Printf("hostname check failed: %v", err)
 		}
 
-		ipaddr := "0.0.0.0"
-		netif, err := net.InterfacesWithContext(ctx)
+		ipaddr, err := ipAddr(ctx)
+		if err != nil {
+			return "", "", err
+		}
+
+		return hostname, ipaddr, nil
+	}))
+	mon.AddInfoScreen("stats", mon.TextScreen(monitor.SystemStats))
+	mon.AddInfoScreen("network", mon.TextScreen(mon.NetRate(monitor.DefaultNetRateInterval)))
+	if *clock {
+		mon.AddInfoScreen("clock", mon.TextScreen(monitor.Clock(*clockDate, *clockTime)), monitor.WithRefresh(time.Second))
+	}
+	if *disks != "" {
+		mon.AddInfoScreen("disks", mon.TextScreen(monitor.DiskUsageScreens(strings.Split(*disks, ",")...)))
+	}
+
+	item := defaultMenu(mon)
+	if *menuFile != "" {
+		item, err = LoadMenu(mon, *menuFile)
+		if err != nil {
+			panic(err)
+		}
+	}
+	mon.SetMenu(item)
+	mon.ShowPosition(*menuPosition)
+	if *idleClock > 0 {
+		mon.SetSecondaryHome(*idleClock, mon.TextScreen(monitor.Clock(*clockDate, *clockTime)), monitor.WithRefresh(time.Second))
+	}
+
+	if *enableSystemd {
+		err = sdNotify("READY=1")
 		if err != nil {
-			return err
+			log.Printf("systemd notify failed: %v", err)
 		}
-		for _, i := range netif {
-			if i.Name == "lo" || strings.HasPrefix(i.Name, "br-") || strings.HasPrefix(i.Name, "docker") || strings.HasPrefix(i.Name, "veth") {
-				continue
-			}
-			if len(i.Addrs) == 0 {
-				continue
-			}
-			ipaddr = i.Addrs[0].Addr
+		if interval := sdWatchdogInterval(); interval > 0 {
+			go sdWatchdog(ctx, m, interval)
 		}
+	}
+
+	<-ctx.Done()
+}
 
-		setDisplay(mon, lcm.DisplayTop, 0, hostname)
-		setDisplay(mon, lcm.DisplayBottom, 0, ipaddr)
-
-		return nil
-	})
-
-	mon.SetMenu(
-		monitor.MenuItem{
-			Name: "Main",
-			SubMenu: []monitor.MenuItem{
-				{
-					Name: "Info",
-					SubMenu: []monitor.MenuItem{
-						{
-							Name: "WIP",
-							Func: func(ctx context.Context) error {
-								return nil
-							},
+// defaultMenu is used when no menu configuration is given.
+func defaultMenu(mon *monitor.Monitor) monitor.MenuItem {
+	return monitor.MenuItem{
+		Name: "Main",
+		SubMenu: []monitor.MenuItem{
+			{
+				Name: "Info",
+				SubMenu: []monitor.MenuItem{
+					{
+						Name: "WIP",
+						Func: func(ctx context.Context) error {
+							return nil
 						},
 					},
 				},
-				{
-					Name: "System",
-					SubMenu: []monitor.MenuItem{
-						{
-							Name:    "Shutdown",
-							Confirm: true,
-							Func: func(ctx context.Context) error {
-								// if mon.Confirm(ctx, "Are you sure?") {
-								// 	setDisplay(mon, lcm.DisplayTop, 0, "Shutting down...")
-								// 	setDisplay(mon, lcm.DisplayBottom, 0, "")
-								// 	return exec.Command("/usr/sbin/shutdown", "-h", "now").Run()
-								// }
-								// mon.Back()
-								return nil
-							},
-						},
-						{
-							Name:    "Restart",
-							Confirm: true,
-							Func: func(ctx context.Context) error {
-								return nil
-							},
-						},
+			},
+			{
+				Name: "System",
+				SubMenu: []monitor.MenuItem{
+					{
+						Name:    "Shutdown",
+						Confirm: true,
+						Func:    powerAction(mon, "Shutting down...", "-h"),
+					},
+					{
+						Name:    "Restart",
+						Confirm: true,
+						Func:    powerAction(mon, "Restarting...", "-r"),
 					},
 				},
-				{
-					Name: program,
-					SubMenu: []monitor.MenuItem{
-						{
-							Name: "Version",
-							Func: func(_ context.Context) error {
-								setDisplay(mon, lcm.DisplayBottom, 0, program+" "+version)
-								time.Sleep(3 * time.Second)
-								return nil
-							},
+			},
+			{
+				Name: program,
+				SubMenu: []monitor.MenuItem{
+					{
+						Name: "Version",
+						Func: func(_ context.Context) error {
+							setDisplay(mon, lcm.DisplayBottom, 0, program+" "+version)
+							time.Sleep(3 * time.Second)
+							return nil
 						},
 					},
 				},
 			},
 		},
-	)
+	}
+}
 
-	<-ctx.Done()
+// ipAddr returns the IP address of the primary network interface.
+func ipAddr(ctx context.Context) (string, error) {
+	i, err := monitor.PrimaryInterface(ctx)
+	if errors.Is(err, monitor.ErrNoInterface) {
+		return "0.0.0.0", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return i.Addrs[0].Addr, nil
 }
 
 func send(m *monitor.Monitor, b lcm.Message) {
@@ -161,7 +206,7 @@ func send(m *monitor.Monitor, b lcm.Message) {
 }
 
 func setDisplay(m *monitor.Monitor, line lcm.DisplayLine, indent int, text string) {
-	b, err := lcm.SetDisplay(line, indent, text)
+	b, err := lcm.SetDisplay(line, indent, lcm.Truncate(lcm.Transliterate(text), 16-indent))
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mafredri/lcm"
+	"github.com/mafredri/lcm/cmd/openlcmd/monitor"
+)
+
+// menuConfig represents the menu configuration file (YAML). A menu item
+// either has sub-items or an action, for example:
+//
+//	name: Main
+//	items:
+//	  - name: System
+//	    items:
+//	      - name: Show IP
+//	        action: show-ip
+//	      - name: Uptime
+//	        action: run-command
+//	        command: [uptime, -p]
+//	        timeout: 5
+//	      - name: Restart
+//	        action: restart
+//	      - name: Shutdown
+//	        action: shutdown
+//
+// Actions that show something on the display do so on the bottom line
+// for a few seconds before returning to the home screen.
+type menuConfig struct {
+	Name string `yaml:"name"`
+	// Confirm asks the user for confirmation before running the
+	// action, shutdown and restart always ask.
+	Confirm bool `yaml:"confirm"`
+	// Action is one of the built-in actions: shutdown, restart,
+	// show-ip or run-command.
+	Action string `yaml:"action"`
+	// Command is the command (and arguments) for run-command, see
+	// (*monitor.Monitor).RunCommand for security implications.
+	Command []string `yaml:"command"`
+	// Timeout for run-command in seconds.
+	Timeout int          `yaml:"timeout"`
+	Items   []menuConfig `yaml:"items"`
+}
+
+// actionShowTime is how long the result of an action is shown.
+const actionShowTime = 3 * time.Second
+
+type menuAction func(mon *monitor.Monitor, c menuConfig) (monitor.UpdateDisplayFunc, error)
+
+var menuActions = map[string]menuAction{
+	"shutdown": func(mon *monitor.Monitor, _ menuConfig) (monitor.UpdateDisplayFunc, error) {
+		return powerAction(mon, "Shutting down...", "-h"), nil
+	},
+	"restart": func(mon *monitor.Monitor, _ menuConfig) (monitor.UpdateDisplayFunc, error) {
+		return powerAction(mon, "Restarting...", "-r"), nil
+	},
+	"show-ip": func(mon *monitor.Monitor, _ menuConfig) (monitor.UpdateDisplayFunc, error) {
+		return func(ctx context.Context) error {
+			ipaddr, err := ipAddr(ctx)
+			if err != nil {
+				return err
+			}
+			setDisplay(mon, lcm.DisplayBottom, 0, ipaddr)
+			time.Sleep(actionShowTime)
+			return nil
+		}, nil
+	},
+	"run-command": func(mon *monitor.Monitor, c menuConfig) (monitor.UpdateDisplayFunc, error) {
+		if len(c.Command) == 0 {
+			return nil, fmt.Errorf("menu item %q: run-command requires command", c.Name)
+		}
+		timeout := time.Duration(c.Timeout) * time.Second
+		return mon.RunCommand(timeout, c.Command[0], c.Command[1:]...), nil
+	},
+}
+
+// powerActions always ask for confirmation, see menuItem.
+var powerActions = map[string]bool{"shutdown": true, "restart": true}
+
+// powerAction runs shutdown with flag, the menu item must ask for
+// confirmation (see MenuItem.Confirm).
+func powerAction(mon *monitor.Monitor, msg, flag string) monitor.UpdateDisplayFunc {
+	return func(ctx context.Context) error {
+		setDisplay(mon, lcm.DisplayTop, 0, msg)
+		setDisplay(mon, lcm.DisplayBottom, 0, "")
+		return exec.Command("/usr/sbin/shutdown", flag, "now").Run()
+	}
+}
+
+// LoadMenu loads the menu configuration from the file at path (YAML),
+// the actions are bound to mon.
+func LoadMenu(mon *monitor.Monitor, path string) (monitor.MenuItem, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return monitor.MenuItem{}, err
+	}
+	var c menuConfig
+	err = yaml.Unmarshal(b, &c)
+	if err != nil {
+		return monitor.MenuItem{}, fmt.Errorf("parse menu %s: %w", path, err)
+	}
+	return c.menuItem(mon)
+}
+
+func (c menuConfig) menuItem(mon *monitor.Monitor) (monitor.MenuItem, error) {
+	item := monitor.MenuItem{
+		Name:    c.Name,
+		Confirm: c.Confirm || powerActions[c.Action],
+	}
+	switch {
+	case c.Action != "" && len(c.Items) > 0:
+		return item, fmt.Errorf("menu item %q: action and items are mutually exclusive", c.Name)
+	case c.Action != "":
+		action, ok := menuActions[c.Action]
+		if !ok {
+			return item, fmt.Errorf("menu item %q: unknown action %q", c.Name, c.Action)
+		}
+		fn, err := action(mon, c)
+		if err != nil {
+			return item, err
+		}
+		item.Func = fn
+	case len(c.Items) > 0:
+		for _, sub := range c.Items {
+			subItem, err := sub.menuItem(mon)
+			if err != nil {
+				return item, err
+			}
+			item.SubMenu = append(item.SubMenu, subItem)
+		}
+	default:
+		return item, fmt.Errorf("menu item %q: must have an action or items", c.Name)
+	}
+	return item, nil
+}
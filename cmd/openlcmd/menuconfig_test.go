@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mafredri/lcm"
+	"github.com/mafredri/lcm/cmd/openlcmd/monitor"
+	"github.com/mafredri/lcm/lcmtest"
+)
+
+func TestLoadMenu(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		want    []string // Names of the System sub-items.
+		confirm []bool
+		wantErr bool
+	}{
+		{
+			name: "YAML",
+			config: `
+name: Main
+items:
+  - name: System
+    items:
+      - name: Show IP
+        action: show-ip
+      - name: Uptime
+        action: run-command
+        command: [uptime, -p]
+        timeout: 5
+        confirm: true
+      - name: Restart
+        action: restart
+`,
+			want:    []string{"Show IP", "Uptime", "Restart"},
+			confirm: []bool{false, true, true},
+		},
+		{
+			name:    "JSON",
+			config:  `{"name": "Main", "items": [{"name": "System", "items": [{"name": "Shutdown", "action": "shutdown"}]}]}`,
+			want:    []string{"Shutdown"},
+			confirm: []bool{true},
+		},
+		{name: "Unknown action", config: "name: Main\naction: reboot\n", wantErr: true},
+		{name: "Missing command", config: "name: Main\naction: run-command\n", wantErr: true},
+		{name: "No action or items", config: "name: Main\n", wantErr: true},
+		{name: "Action and items", config: "name: Main\naction: show-ip\nitems:\n  - name: A\n    action: show-ip\n", wantErr: true},
+		{name: "Invalid", config: "name: [Main\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "menu.yaml")
+			if err := os.WriteFile(path, []byte(tt.config), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			l := lcm.OpenConn(lcmtest.NewFakeMCU())
+			defer l.Close()
+			mon := monitor.New(context.Background(), "test", l, nil)
+			defer mon.Close()
+
+			got, err := LoadMenu(mon, path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadMenu() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.Name != "Main" || len(got.SubMenu) != 1 || got.SubMenu[0].Name != "System" {
+				t.Fatalf("LoadMenu() = %+v, want Main > System", got)
+			}
+			items := got.SubMenu[0].SubMenu
+			if len(items) != len(tt.want) {
+				t.Fatalf("System has %d items, want %d", len(items), len(tt.want))
+			}
+			for i, item := range items {
+				if item.Name != tt.want[i] || item.Confirm != tt.confirm[i] || item.Func == nil {
+					t.Errorf("item %d = {Name: %q, Confirm: %v, Func: %v}, want {Name: %q, Confirm: %v, Func: set}",
+						i, item.Name, item.Confirm, item.Func != nil, tt.want[i], tt.confirm[i])
+				}
+			}
+		})
+	}
+}
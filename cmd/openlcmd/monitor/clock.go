@@ -0,0 +1,41 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Default layouts for Clock, see time.Layout.
+const (
+	DefaultClockDateLayout = "Mon 2006-01-02"
+	DefaultClockTimeLayout = "15:04:05"
+)
+
+// Clock returns a TextFunc that shows the current date on the top line
+// and the time centered on the bottom line, formatted using the layouts
+// (see time.Layout). Empty layouts use the defaults.
+//
+// Use WithRefresh to keep the clock ticking while it is shown:
+//
+//	mon.AddInfoScreen("clock", mon.TextScreen(monitor.Clock("", "")), monitor.WithRefresh(time.Second))
+func Clock(dateLayout, timeLayout string) TextFunc {
+	if dateLayout == "" {
+		dateLayout = DefaultClockDateLayout
+	}
+	if timeLayout == "" {
+		timeLayout = DefaultClockTimeLayout
+	}
+	return func(_ context.Context) (top, bottom string, err error) {
+		now := time.Now()
+		return now.Format(dateLayout), center(now.Format(timeLayout), 16), nil
+	}
+}
+
+// center pads s with spaces to center it within width.
+func center(s string, width int) string {
+	if len(s) >= width {
+		return s
+	}
+	return fmt.Sprintf("%*s", (width+len(s))/2, s)
+}
@@ -0,0 +1,166 @@
+package monitor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"github.com/mafredri/lcm"
+)
+
+const (
+	// DefaultCommandTimeout is the default timeout for RunCommand.
+	DefaultCommandTimeout = 10 * time.Second
+	// commandShowTime is how long the command output is shown.
+	commandShowTime = 5 * time.Second
+)
+
+// RunCommand returns a MenuItem function that runs the command and shows
+// the first line of its output on the bottom line of the display, long
+// output is scrolled. When the command fails, "ERR <exit code>" is shown
+// instead. A spinner is shown in the last cell of the bottom line while
+// the command is running.
+//
+// The command is killed (along with its process group) when the timeout
+// is reached or the context is canceled, e.g. when the display goes
+// idle or the program is shutting down. The spinner and output are not
+// counted as activity, so the display can still go idle, and nothing
+// is shown once the context is canceled.
+//
+// Security: the command runs with the same privileges as the program
+// (usually root) and can be triggered by anyone with physical access to
+// the buttons. The command is not interpreted by a shell, but only
+// trusted commands should be configured and the configuration should
+// only be writable by root.
+func (m *Monitor) RunCommand(timeout time.Duration, name string, arg ...string) UpdateDisplayFunc {
+	if timeout <= 0 {
+		timeout = DefaultCommandTimeout
+	}
+	return func(ctx context.Context) error {
+		cmdCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		spinCtx, stopSpin := context.WithCancel(cmdCtx)
+		spinDone := make(chan struct{})
+		go func() {
+			defer close(spinDone)
+			m.spin(spinCtx, lcm.DisplayBottom, 15)
+		}()
+
+		out, err := runCommand(cmdCtx, name, arg...)
+		stopSpin()
+		<-spinDone
+
+		text := firstLine(out)
+		if err != nil {
+			var exitErr *exec.ExitError
+			switch {
+			case cmdCtx.Err() == context.DeadlineExceeded:
+				text = "ERR timeout"
+			case errors.As(err, &exitErr):
+				text = fmt.Sprintf("ERR %d", exitErr.ExitCode())
+			default:
+				text = "ERR"
+			}
+			err = fmt.Errorf("run %s: %w", name, err)
+		}
+
+		showErr := m.show(ctx, lcm.DisplayBottom, text, commandShowTime)
+		if err == nil && ctx.Err() == nil {
+			err = showErr
+		}
+		return err
+	}
+}
+
+// runCommand runs the command in its own process group so that any
+// children are also killed when the context is canceled.
+func runCommand(ctx context.Context, name string, arg ...string) ([]byte, error) {
+	var out bytes.Buffer
+	cmd := exec.Command(name, arg...)
+	cmd.Stdout = &out
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	err := cmd.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		err = <-done
+	}
+	return out.Bytes(), err
+}
+
+// spin shows a spinner in the given cell until ctx is canceled.
+func (m *Monitor) spin(ctx context.Context, line lcm.DisplayLine, column int) {
+	s, err := lcm.NewSpinner(line, column, "")
+	if err != nil {
+		panic(err)
+	}
+
+	t := time.NewTicker(lcm.DefaultSpinnerDelay)
+	defer t.Stop()
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		// Bypass Send, the spinner is not user activity.
+		if err := m.lcm.Send(s.Next()); err != nil {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// show the text on the line for duration d, scrolling it if necessary.
+func (m *Monitor) show(ctx context.Context, line lcm.DisplayLine, text string, d time.Duration) error {
+	deadline := time.NewTimer(d)
+	defer deadline.Stop()
+
+	s := lcm.NewScroller(line, lcm.Transliterate(text))
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		b, delay := s.NextDelay()
+		// Bypass Send, it would wake the display after it has
+		// gone idle (and canceled ctx).
+		err := m.lcm.Send(b)
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline.C:
+			return nil
+		case <-time.After(delay):
+		}
+	}
+}
+
+func firstLine(b []byte) string {
+	s := bufio.NewScanner(bytes.NewReader(b))
+	if s.Scan() {
+		return s.Text()
+	}
+	return ""
+}
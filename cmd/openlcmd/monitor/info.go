@@ -0,0 +1,222 @@
+package monitor
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultInfoInterval is how long each info screen is shown before
+// rotating to the next one.
+const DefaultInfoInterval = 5 * time.Second
+
+// refreshResolution is how often screens are checked for refresh.
+const refreshResolution = time.Second
+
+type infoScreen struct {
+	name    string
+	fn      UpdateDisplayFunc
+	refresh time.Duration
+}
+
+// InfoOption configures an info screen.
+type InfoOption func(*infoScreen)
+
+// WithRefresh redraws the screen every d while it is shown and the
+// display is on, e.g. for a clock. The resolution is one second.
+func WithRefresh(d time.Duration) InfoOption {
+	return func(is *infoScreen) {
+		is.refresh = d
+	}
+}
+
+func newInfoScreen(name string, fn UpdateDisplayFunc, opts ...InfoOption) infoScreen {
+	is := infoScreen{name: name, fn: fn}
+	for _, o := range opts {
+		o(&is)
+	}
+	return is
+}
+
+// infoScreens keeps track of the home screen and the info screens
+// that are rotated on the home display.
+type infoScreens struct {
+	mu      sync.Mutex
+	home    *infoScreen
+	screens []infoScreen
+	cur     int
+	drawn   time.Time // Last time the current screen was drawn.
+
+	// secondary replaces the home display (and rotation) while
+	// idle is set, see SetSecondaryHome.
+	secondary *infoScreen
+	idle      bool
+}
+
+func (s *infoScreens) setHome(is *infoScreen) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.home = is
+}
+
+func (s *infoScreens) setSecondary(is *infoScreen) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.secondary = is
+}
+
+// setIdle shows (or hides) the secondary home screen, returns the
+// previous value.
+func (s *infoScreens) setIdle(idle bool) (prev bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev = s.idle
+	s.idle = idle && s.secondary != nil
+	return prev
+}
+
+// current returns the screen that is shown, if any, s.mu must be held.
+func (s *infoScreens) current() *infoScreen {
+	if s.idle {
+		return s.secondary
+	}
+	all := s.all()
+	if s.cur < len(all) {
+		return &all[s.cur]
+	}
+	return nil
+}
+
+func (s *infoScreens) add(is infoScreen) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.screens = append(s.screens, is)
+}
+
+// all returns the home screen (when set) followed by the info screens.
+func (s *infoScreens) all() []infoScreen {
+	var all []infoScreen
+	if s.home != nil {
+		all = append(all, *s.home)
+	}
+	return append(all, s.screens...)
+}
+
+// len returns the number of screens that are rotated, the secondary
+// home screen is never rotated.
+func (s *infoScreens) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.idle {
+		return 1
+	}
+	return len(s.all())
+}
+
+// page moves n screens forward (or backward), wrapping around.
+func (s *infoScreens) page(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l := len(s.all())
+	if l == 0 {
+		return
+	}
+	s.cur = ((s.cur+n)%l + l) % l
+}
+
+// reset to the first screen.
+func (s *infoScreens) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cur = 0
+}
+
+// due reports if the current screen should be refreshed.
+func (s *infoScreens) due(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cur := s.current()
+	if cur == nil || cur.refresh <= 0 {
+		return false
+	}
+	// Allow for some jitter since the check happens at the same
+	// resolution as the refresh.
+	return now.Sub(s.drawn)+refreshResolution/2 >= cur.refresh
+}
+
+// draw the current screen.
+func (s *infoScreens) draw(ctx context.Context) error {
+	s.mu.Lock()
+	var fn UpdateDisplayFunc
+	if cur := s.current(); cur != nil {
+		fn = cur.fn
+	}
+	s.drawn = time.Now()
+	s.mu.Unlock()
+
+	if fn == nil {
+		return nil
+	}
+	return fn(ctx)
+}
+
+// WithInfoInterval sets how long each info screen is shown before
+// rotating to the next (default DefaultInfoInterval).
+func WithInfoInterval(d time.Duration) Option {
+	return func(m *Monitor) {
+		m.infoInterval = d
+	}
+}
+
+// AddInfoScreen adds an info screen that is shown on the home display,
+// the screens (starting with the one set by SetHome) are rotated when
+// the menu is not open. Up and Down can be used to page between the
+// screens manually.
+func (m *Monitor) AddInfoScreen(name string, fn UpdateDisplayFunc, opts ...InfoOption) {
+	m.info.add(newInfoScreen(name, fn, opts...))
+}
+
+// rotate the info screens while the home display is shown.
+func (m *Monitor) rotate() {
+	t := time.NewTicker(m.infoInterval)
+	defer t.Stop()
+	refresh := time.NewTicker(refreshResolution)
+	defer refresh.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-m.pageC:
+			t.Reset(m.infoInterval)
+		case <-t.C:
+			if m.DisplayIsOff() || m.info.len() < 2 {
+				continue
+			}
+			m.menu.redrawHome(func() { m.info.page(1) })
+		case now := <-refresh.C:
+			if m.DisplayIsOff() || !m.info.due(now) {
+				continue
+			}
+			m.menu.redrawHome(func() {})
+		}
+	}
+}
+
+// page between info screens manually, returns false if the home display
+// is not shown.
+func (m *Monitor) page(n int) bool {
+	if m.info.len() < 2 {
+		return false
+	}
+	ok := m.menu.redrawHome(func() { m.info.page(n) })
+	if ok {
+		select {
+		case m.pageC <- struct{}{}:
+		default:
+		}
+	}
+	return ok
+}
@@ -4,36 +4,132 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/mafredri/lcm"
 )
 
+// menuEmpty is shown instead of the selected item when the submenu is
+// empty.
+const menuEmpty = "(empty)"
+
 type menuState struct {
 	index   int
 	item    *MenuItem
 	confirm bool
+	// edit is set when editing item.Value, value is the pending
+	// (uncommitted) value.
+	edit  bool
+	value int
+}
+
+// display is the part of *lcm.LCM used by the menu.
+type display interface {
+	Send(lcm.Message) error
+	SetLines(top, bottom string) error
 }
 
 type menu struct {
-	lcm     *lcm.LCM
+	mu      sync.Mutex
+	ctx     context.Context
+	lcm     display
 	home    UpdateDisplayFunc
 	history []menuState
 	state   menuState
 	menu    *MenuItem
+
+	// busy is set while a MenuItem.Func is running, button presses
+	// are ignored unless a confirmation prompt is shown. The function
+	// is canceled via cancel when the menu is closed.
+	busy   bool
+	cancel context.CancelFunc
+	// answer resolves the current confirmation prompt, prev is the
+	// state that is restored once answered.
+	answer func(ok bool)
+	prev   menuState
+
+	// stopScroll stops scrolling of long names, if any.
+	stopScroll context.CancelFunc
+	// showPosition shows the position indicator (e.g. 2/5).
+	showPosition bool
 }
 
-func newMenu(lcm *lcm.LCM, home UpdateDisplayFunc, item MenuItem) *menu {
-	m := &menu{lcm: lcm, home: home, menu: &item}
+func newMenu(ctx context.Context, lcm display, home UpdateDisplayFunc, item MenuItem) *menu {
+	m := &menu{ctx: ctx, lcm: lcm, home: home, menu: &item}
 	return m
 }
 
+// set the menu item, closing the current menu (if open).
+func (m *menu) set(item MenuItem) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.resolve(false)
+	m.history = nil
+	m.state = menuState{}
+	m.menu = &item
+}
+
 func (m *menu) close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.resolve(false)
+	m.history = nil
+	m.state = menuState{}
+	m.draw()
+}
+
+// closeIdle returns to the home screen due to inactivity, unless a
+// function is running.
+func (m *menu) closeIdle() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.busy || m.state.item == nil {
+		return
+	}
+	m.resolve(false)
+	m.history = nil
 	m.state = menuState{}
 	m.draw()
 }
 
+// redrawHome calls fn and redraws the home screen if it is shown,
+// returns false otherwise.
+func (m *menu) redrawHome(fn func()) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.busy || m.state.item != nil {
+		return false
+	}
+	fn()
+	m.draw()
+	return true
+}
+
 func (m *menu) up() {
-	if m.state.item == nil {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.state.item == nil || m.ignore() {
+		return
+	}
+	if m.state.edit {
+		m.state.value = m.state.item.Value.step(m.state.value, 1)
+		m.draw()
+		return
+	}
+	if len(m.state.item.SubMenu) == 0 {
 		return
 	}
 	m.state.index--
@@ -44,10 +140,24 @@ func (m *menu) up() {
 }
 
 func (m *menu) down() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.ignore() {
+		return
+	}
 	if m.state.item == nil {
 		m.draw()
 		return
 	}
+	if m.state.edit {
+		m.state.value = m.state.item.Value.step(m.state.value, -1)
+		m.draw()
+		return
+	}
+	if len(m.state.item.SubMenu) == 0 {
+		return
+	}
 	m.state.index++
 	if m.state.index > len(m.state.item.SubMenu)-1 {
 		m.state.index = 0
@@ -56,6 +166,19 @@ func (m *menu) down() {
 }
 
 func (m *menu) back() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.ignore() {
+		return
+	}
+	if m.state.confirm {
+		m.resolve(false)
+		m.draw()
+		return
+	}
+	// Back pops exactly one level, from the top menu it returns
+	// home.
 	if len(m.history) == 0 {
 		m.state = menuState{}
 	} else {
@@ -66,77 +189,262 @@ func (m *menu) back() {
 }
 
 func (m *menu) enter() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.ignore() {
+		return
+	}
 	if m.state.item == nil {
-		m.state.item = m.menu
+		// The top menu is the root of the history, back from the
+		// top menu always returns home.
+		m.history = nil
+		m.state = menuState{item: m.menu}
 		m.draw()
 		return
 	}
 	if m.state.confirm {
-		m.state.item.SubMenu[m.state.index].Func(context.Background())
+		// Yes is the first option.
+		m.resolve(m.state.index == 0)
+		m.draw()
+		return
+	}
+	if m.state.edit {
+		v := m.state.item.Value
+		if v.OnChange != nil {
+			err := v.OnChange(m.state.value)
+			if err != nil {
+				log.Printf("menu item %q: %v", m.state.item.Name, err)
+			} else {
+				v.Current = m.state.value
+			}
+		} else {
+			v.Current = m.state.value
+		}
+		m.state = m.history[len(m.history)-1]
+		m.history = m.history[:len(m.history)-1]
+		m.draw()
 		return
 	}
 
-	m.history = append(m.history, m.state)
-	m.state = menuState{
-		item: &m.state.item.SubMenu[m.state.index],
+	item := m.selected()
+	if item == nil {
+		return // Empty submenu.
+	}
+	if item.Value != nil {
+		m.history = append(m.history, m.state)
+		m.state = menuState{item: item, edit: true, value: item.Value.Current}
+		m.draw()
+		return
 	}
-	if m.state.item.Func != nil {
-		if m.state.item.Confirm {
-			m.confirm()
+	if item.Func != nil {
+		fn := item.Func
+		if item.Confirm {
+			m.ask("Are you sure?", func(ok bool) {
+				if ok {
+					m.run(fn)
+				}
+			})
+			m.draw()
 			return
 		}
-		err := m.state.item.Func(context.Background())
+		m.run(fn)
+		return
+	}
+	if len(item.SubMenu) == 0 {
+		// Nothing to do, e.g. a misconfigured item.
+		log.Printf("menu item %q: no function or submenu", item.Name)
+		return
+	}
+
+	// Snapshot the current state (including the selected index) so
+	// that back returns to the item we entered from.
+	m.history = append(m.history, m.state)
+	m.state = menuState{item: item}
+	m.draw()
+}
+
+// selected returns the selected item, or nil if the submenu is empty
+// (or was emptied).
+func (m *menu) selected() *MenuItem {
+	if m.state.index < 0 || m.state.index >= len(m.state.item.SubMenu) {
+		return nil
+	}
+	return &m.state.item.SubMenu[m.state.index]
+}
+
+func (m *menu) setShowPosition(show bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.showPosition = show
+}
+
+// ignore reports if button presses should be ignored.
+func (m *menu) ignore() bool {
+	return m.busy && !m.state.confirm
+}
+
+// run the function in the background, the menu is closed once it
+// completes.
+func (m *menu) run(fn UpdateDisplayFunc) {
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.busy = true
+	m.cancel = cancel
+	go func() {
+		defer cancel()
+
+		err := fn(ctx)
 		if err != nil {
 			log.Println(err)
 		}
 
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		m.busy = false
+		m.cancel = nil
 		m.history = nil
 		m.state = menuState{}
-	}
-
-	m.draw()
+		m.draw()
+	}()
 }
 
 func (m *menu) draw() {
+	if m.stopScroll != nil {
+		m.stopScroll()
+		m.stopScroll = nil
+	}
+	if m.busy && !m.state.confirm {
+		// The running function owns the display.
+		return
+	}
 	if m.state.item == nil {
-		m.home(context.Background())
+		if m.home != nil {
+			m.home(m.ctx)
+		}
+		return
+	}
+
+	top := lcm.Transliterate(m.state.item.Name)
+	var bottom string
+	if m.state.edit {
+		bottom = lcm.Transliterate(fmt.Sprintf("<%s>", m.state.item.Value.format(m.state.value)))
+		bottom = fmt.Sprintf("%*s", (16+len(bottom))/2, bottom)
+	} else if item := m.selected(); item != nil {
+		bottom = lcm.Transliterate(fmt.Sprintf(">%s", item.Name))
+	} else {
+		bottom = menuEmpty
+	}
+	if m.showPosition && !m.state.edit && bottom != menuEmpty {
+		// Long names are truncated rather than scrolled to make
+		// room for the position indicator.
+		pos := fmt.Sprintf("%d/%d", m.state.index+1, len(m.state.item.SubMenu))
+		width := 16 - len(pos) - 1
+		bottom = fmt.Sprintf("%-*s %s", width, lcm.Truncate(bottom, width), pos)
+	}
+	if len(top) <= 16 && len(bottom) <= 16 {
+		m.lcm.SetLines(top, bottom)
 		return
 	}
-	top, _ := lcm.SetDisplay(lcm.DisplayTop, 0, m.state.item.Name)
-	bottom, _ := lcm.SetDisplay(lcm.DisplayBottom, 0, fmt.Sprintf(">%s", m.state.item.SubMenu[m.state.index].Name))
-	m.lcm.Send(top)
-	m.lcm.Send(bottom)
+
+	// Scroll long names while the item is shown.
+	ms := lcm.NewMultiScroller(
+		lcm.NewScroller(lcm.DisplayTop, top),
+		lcm.NewScroller(lcm.DisplayBottom, bottom),
+	)
+	msgs, delay := ms.Next()
+	for _, b := range msgs {
+		m.lcm.Send(b)
+	}
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.stopScroll = cancel
+	go m.scroll(ctx, ms, delay)
+}
+
+// scroll the long names until the context is canceled (on next draw).
+func (m *menu) scroll(ctx context.Context, ms *lcm.MultiScroller, delay time.Duration) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		var msgs []lcm.Message
+		msgs, delay = ms.Next()
+
+		// Hold the lock so that a stale frame is never drawn after
+		// the next draw.
+		m.mu.Lock()
+		if ctx.Err() == nil {
+			for _, b := range msgs {
+				m.lcm.Send(b)
+			}
+		}
+		m.mu.Unlock()
+	}
 }
 
-func (m *menu) confirm() {
-	fn := m.state.item.Func
+// ask shows a Yes/No prompt with msg, answer is called once the user
+// has made a choice (or the prompt was canceled) after the previous
+// state has been restored.
+func (m *menu) ask(msg string, answer func(ok bool)) {
+	m.resolve(false) // Cancel the previous prompt, if any.
+
+	m.prev = m.state
+	m.answer = answer
 	m.state = menuState{
 		confirm: true,
 		item: &MenuItem{
-			Name: "Are you sure?",
+			Name: msg,
 			SubMenu: []MenuItem{
-				{
-					Name: "Yes",
-					Func: func(ctx context.Context) error {
-						err := fn(ctx)
-						m.history = nil
-						m.state = menuState{}
-						m.draw()
-						return err
-					},
-				},
-				{
-					Name: "No",
-					Func: func(context.Context) error {
-						// Restore previous state.
-						m.back()
-						return nil
-					},
-				},
+				{Name: "Yes"},
+				{Name: "No"},
 			},
 		},
 	}
+}
+
+// resolve the current prompt (if any) with ok.
+func (m *menu) resolve(ok bool) {
+	if m.answer == nil {
+		return
+	}
+	answer := m.answer
+	m.answer = nil
+	m.state = m.prev
+	m.prev = menuState{}
+	answer(ok)
+}
+
+// confirm shows a Yes/No prompt and blocks until the user has made a
+// choice. Returns false if the prompt was canceled.
+func (m *menu) confirm(ctx context.Context, msg string) bool {
+	c := make(chan bool, 1)
+	m.mu.Lock()
+	m.ask(msg, func(ok bool) { c <- ok })
 	m.draw()
+	m.mu.Unlock()
+
+	select {
+	case ok := <-c:
+		return ok
+	case <-ctx.Done():
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		select {
+		case ok := <-c:
+			return ok
+		default:
+			// Our prompt is still pending.
+			m.resolve(false)
+			m.draw()
+			return <-c
+		}
+	}
 }
 
 type MenuItem struct {
@@ -144,4 +452,43 @@ type MenuItem struct {
 	Confirm bool
 	Func    UpdateDisplayFunc
 	SubMenu []MenuItem
+	// Value makes the item editable, Up and Down change the value,
+	// Enter commits and Back cancels.
+	Value *Value
+}
+
+// Value is an editable value within [Min, Max], e.g. brightness.
+type Value struct {
+	Min, Max int
+	Step     int // Defaults to 1.
+	Current  int
+	// Labels is used to show the value, when set, e.g. for enums:
+	// Labels[Current-Min].
+	Labels []string
+	// OnChange is called with the new value on commit, Current is
+	// only updated if it returns no error.
+	OnChange func(int) error
+}
+
+// step the value n steps, clamped to [Min, Max].
+func (v *Value) step(cur, n int) int {
+	step := v.Step
+	if step <= 0 {
+		step = 1
+	}
+	cur += n * step
+	if cur < v.Min {
+		cur = v.Min
+	}
+	if cur > v.Max {
+		cur = v.Max
+	}
+	return cur
+}
+
+func (v *Value) format(cur int) string {
+	if i := cur - v.Min; i >= 0 && i < len(v.Labels) {
+		return v.Labels[i]
+	}
+	return strconv.Itoa(cur)
 }
@@ -0,0 +1,183 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mafredri/lcm"
+)
+
+type fakeDisplay struct {
+	top, bottom string
+}
+
+func (d *fakeDisplay) Send(lcm.Message) error { return nil }
+
+func (d *fakeDisplay) SetLines(top, bottom string) error {
+	d.top, d.bottom = top, bottom
+	return nil
+}
+
+func testMenuItem() MenuItem {
+	return MenuItem{
+		Name: "Main",
+		SubMenu: []MenuItem{
+			{Name: "A", SubMenu: []MenuItem{{Name: "A1"}, {Name: "A2"}}},
+			{Name: "B", SubMenu: []MenuItem{{Name: "B1"}, {Name: "B2"}, {Name: "B3"}}},
+			{Name: "C", SubMenu: []MenuItem{{Name: "C1"}}},
+		},
+	}
+}
+
+func Test_menu_back(t *testing.T) {
+	tests := []struct {
+		name       string
+		actions    string // u(p), d(own), e(nter), b(ack).
+		wantTop    string
+		wantBottom string
+		wantIndex  int
+	}{
+		{"Back to first", "eedb", "Main", ">A", 0},
+		{"Back to second", "edeb", "Main", ">B", 1},
+		{"Back after navigating submenu", "eddeddb", "Main", ">C", 2},
+		{"Back after wrapping", "euedb", "Main", ">C", 2},
+		{"Navigate after back", "edeb" + "d", "Main", ">C", 2},
+		{"Enter again after back", "edeb" + "e", "B", ">B1", 0},
+		{"Back twice", "edebb", "", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &fakeDisplay{}
+			m := newMenu(context.Background(), d, nil, testMenuItem())
+
+			for _, a := range tt.actions {
+				switch a {
+				case 'u':
+					m.up()
+				case 'd':
+					m.down()
+				case 'e':
+					m.enter()
+				case 'b':
+					m.back()
+				}
+			}
+
+			if tt.wantTop == "" {
+				if m.state.item != nil {
+					t.Errorf("menu open on %q, want closed", m.state.item.Name)
+				}
+				return
+			}
+			if d.top != tt.wantTop || d.bottom != tt.wantBottom {
+				t.Errorf("display = %q, %q; want %q, %q", d.top, d.bottom, tt.wantTop, tt.wantBottom)
+			}
+			if m.state.index != tt.wantIndex {
+				t.Errorf("index = %d; want %d", m.state.index, tt.wantIndex)
+			}
+		})
+	}
+}
+
+func Test_menu_backSteps(t *testing.T) {
+	d := &fakeDisplay{}
+	home := func(context.Context) error { return d.SetLines("home", "") }
+	m := newMenu(context.Background(), d, home, testMenuItem())
+	m.history = []menuState{{item: &MenuItem{Name: "Stale"}}} // Never reached.
+
+	steps := []struct {
+		action     string // u(p), d(own), e(nter), b(ack).
+		wantTop    string
+		wantBottom string
+	}{
+		{"e", "Main", ">A"},
+		{"d", "Main", ">B"},
+		{"e", "B", ">B1"},
+		{"d", "B", ">B2"},
+		{"b", "Main", ">B"},
+		{"b", "home", ""},
+		{"b", "home", ""},
+		{"e", "Main", ">A"},
+		{"e", "A", ">A1"},
+		{"b", "Main", ">A"},
+		{"b", "home", ""},
+	}
+	for i, s := range steps {
+		switch s.action {
+		case "u":
+			m.up()
+		case "d":
+			m.down()
+		case "e":
+			m.enter()
+		case "b":
+			m.back()
+		}
+		if d.top != s.wantTop || d.bottom != s.wantBottom {
+			t.Errorf("step %d (%s): display = %q, %q; want %q, %q", i, s.action, d.top, d.bottom, s.wantTop, s.wantBottom)
+		}
+	}
+}
+
+func Test_menu_empty(t *testing.T) {
+	tests := []struct {
+		name       string
+		item       MenuItem
+		actions    string // u(p), d(own), e(nter), b(ack).
+		wantTop    string
+		wantBottom string
+	}{
+		{"Empty top menu", MenuItem{Name: "Main"}, "eudee", "Main", "(empty)"}, // No position.
+		{"Empty submenu", MenuItem{Name: "Main", SubMenu: []MenuItem{{Name: "A", SubMenu: []MenuItem{}}}}, "e", "Main", ">A           1/1"},
+		{"Leaf without func", MenuItem{Name: "Main", SubMenu: []MenuItem{{Name: "A"}, {Name: "B"}}}, "eeud", "Main", ">A           1/2"},
+		{"Back after empty", MenuItem{Name: "Main", SubMenu: []MenuItem{{Name: "A"}}}, "eeeb", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &fakeDisplay{}
+			m := newMenu(context.Background(), d, nil, tt.item)
+			m.showPosition = true // Not shown when empty.
+
+			for _, a := range tt.actions {
+				switch a {
+				case 'u':
+					m.up()
+				case 'd':
+					m.down()
+				case 'e':
+					m.enter()
+				case 'b':
+					m.back()
+				}
+			}
+
+			if tt.wantTop == "" {
+				if m.state.item != nil {
+					t.Errorf("menu open on %q, want closed", m.state.item.Name)
+				}
+				return
+			}
+			if d.top != tt.wantTop || d.bottom != tt.wantBottom {
+				t.Errorf("display = %q, %q; want %q, %q", d.top, d.bottom, tt.wantTop, tt.wantBottom)
+			}
+		})
+	}
+
+	// A submenu that is emptied while shown.
+	d := &fakeDisplay{}
+	item := testMenuItem()
+	m := newMenu(context.Background(), d, nil, item)
+	m.enter()
+	m.down()
+	m.menu.SubMenu = nil
+	m.enter()
+	m.down()
+	m.draw()
+	if d.bottom != "(empty)" {
+		t.Errorf("bottom = %q, want %q", d.bottom, "(empty)")
+	}
+	m.back()
+	if m.state.item != nil {
+		t.Errorf("menu open on %q, want closed", m.state.item.Name)
+	}
+}
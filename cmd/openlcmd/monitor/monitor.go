@@ -2,14 +2,27 @@ package monitor
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bendahl/uinput"
 	"github.com/mafredri/lcm"
 )
 
-const activityTimeout = 15 * time.Second
+const (
+	// DefaultIdleTimeout is how long the display stays on without
+	// activity before it is turned off.
+	DefaultIdleTimeout = 15 * time.Second
+	// DefaultMenuTimeout is how long the menu stays open without
+	// button activity before returning to the home screen.
+	DefaultMenuTimeout = 10 * time.Second
+	// sendGrace is the time after a send during which the display
+	// is not turned off, even if the idle timeout has passed.
+	sendGrace = time.Second
+)
 
 type UpdateDisplayFunc func(context.Context) error
 
@@ -19,16 +32,57 @@ type Monitor struct {
 	lcm    *lcm.LCM
 	p      *lcm.Power
 	kbd    uinput.Keyboard
-	off    bool
+	off    int32 // Accessed atomically.
 	home   UpdateDisplayFunc
 	menu   *menu
-	actC   chan struct{}
+	act    *lcm.ActivityTracker
+	secAct *lcm.ActivityTracker // Button activity, see SetSecondaryHome.
+	chord  *lcm.ChordDetector
+	chords map[lcm.Chord]func()
+
+	recvDone chan struct{} // Closed when recv returns.
+
+	// mu serializes sends with turning the display off (or on), see
+	// Send and Wake.
+	mu       sync.Mutex
+	lastSend time.Time
+
+	showPosition bool
+	idleTimeout  time.Duration
+	menuTimeout  time.Duration
+	menuTimer    *time.Timer
+	info         infoScreens
+	infoInterval time.Duration
+	pageC        chan struct{}
+}
+
+// Option configures the Monitor.
+type Option func(*Monitor)
+
+// WithIdleTimeout sets how long the display stays on without activity
+// before it is turned off (default DefaultIdleTimeout). Zero keeps the
+// display always on.
+func WithIdleTimeout(d time.Duration) Option {
+	return func(m *Monitor) {
+		m.idleTimeout = d
+	}
 }
 
-func New(ctx context.Context, name string, l *lcm.LCM, kbd uinput.Keyboard) *Monitor {
+// WithMenuTimeout sets how long the menu stays open without button
+// activity before returning to the home screen (default
+// DefaultMenuTimeout). Zero disables the timeout.
+func WithMenuTimeout(d time.Duration) Option {
+	return func(m *Monitor) {
+		m.menuTimeout = d
+	}
+}
+
+func New(ctx context.Context, name string, l *lcm.LCM, kbd uinput.Keyboard, opts ...Option) *Monitor {
 	p, err := lcm.NewPower(name)
 	if err != nil {
 		log.Printf("power cycling disabled: %v", err)
+	} else {
+		log.Printf("power cycling enabled (gpio %s)", p.Backend())
 	}
 
 	ctx, cancel := context.WithCancel(ctx)
@@ -39,38 +93,185 @@ func New(ctx context.Context, name string, l *lcm.LCM, kbd uinput.Keyboard) *Mon
 		lcm:    l,
 		p:      p,
 		kbd:    kbd,
-		menu:   &menu{},
-		actC:   make(chan struct{}),
+		chord:  lcm.NewChordDetector(lcm.DefaultChordWindow),
+		chords: make(map[lcm.Chord]func()),
+
+		recvDone: make(chan struct{}),
+
+		idleTimeout:  DefaultIdleTimeout,
+		menuTimeout:  DefaultMenuTimeout,
+		infoInterval: DefaultInfoInterval,
+		pageC:        make(chan struct{}, 1),
 	}
+	for _, o := range opts {
+		o(m)
+	}
+	// The menu is created up front and never replaced, it's shared
+	// by the goroutines below and guarded by its own mutex.
+	m.menu = &menu{ctx: ctx, lcm: l, home: m.info.draw, showPosition: m.showPosition}
+	m.act = lcm.NewActivityTracker(m.idleTimeout)
+
+	m.menuTimer = time.AfterFunc(time.Hour, func() { m.menu.closeIdle() })
+	m.menuTimer.Stop()
 
 	go m.idle()
 	go m.recv()
+	go m.rotate()
 
 	return m
 }
 
-func (m *Monitor) SetHome(fn UpdateDisplayFunc) {
+// SetHome sets the home screen, it is shown when the menu is closed.
+// See AddInfoScreen for additional screens.
+func (m *Monitor) SetHome(fn UpdateDisplayFunc, opts ...InfoOption) {
 	m.home = fn
+	is := newInfoScreen("home", fn, opts...)
+	m.info.setHome(&is)
 }
 
+// SetSecondaryHome sets a screen that replaces the home screen (and
+// info screens) after there have been no button presses for the
+// duration of after, e.g. to show a clock while the NAS is idle. The
+// display stays on, after should be shorter than the idle timeout (see
+// WithIdleTimeout). The home screen is shown again on the next button
+// press, the press is otherwise ignored. Should be called during setup.
+func (m *Monitor) SetSecondaryHome(after time.Duration, fn UpdateDisplayFunc, opts ...InfoOption) {
+	is := newInfoScreen("secondary", fn, opts...)
+	m.info.setSecondary(&is)
+	m.secAct = lcm.NewActivityTracker(after)
+	m.secAct.Touch()
+	go m.secondaryIdle(m.secAct)
+}
+
+// secondaryIdle shows the secondary home screen when there has been no
+// button activity.
+func (m *Monitor) secondaryIdle(act *lcm.ActivityTracker) {
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-act.Idle():
+			ok := m.menu.redrawHome(func() { m.info.setIdle(true) })
+			if !ok {
+				// The menu is open, try again later.
+				act.Touch()
+			}
+		}
+	}
+}
+
+// leaveSecondary shows the home screen if the secondary home screen is
+// shown, returns false otherwise.
+func (m *Monitor) leaveSecondary() bool {
+	if m.secAct == nil {
+		return false
+	}
+	m.secAct.Touch()
+	if !m.info.setIdle(false) {
+		return false
+	}
+	m.info.reset()
+	return m.menu.redrawHome(func() {})
+}
+
+// SetMenu sets the menu that is opened by pressing Enter on the home
+// screen, an open menu is closed.
 func (m *Monitor) SetMenu(item MenuItem) {
-	m.menu = newMenu(m.lcm, m.home, item)
+	m.menu.set(item)
 	if m.home != nil {
 		m.home(m.ctx)
+		m.activity()
 	}
 }
 
+// ShowPosition toggles the position indicator (e.g. "2/5") shown on the
+// bottom line of the menu (default disabled). Long item names are
+// truncated instead of scrolled while it is shown.
+func (m *Monitor) ShowPosition(show bool) {
+	m.showPosition = show
+	m.menu.setShowPosition(show)
+}
+
+// BindChord binds the chord (two buttons pressed in quick succession)
+// to fn. The individual button presses are handled as usual before fn
+// is called. Should be called during setup.
+func (m *Monitor) BindChord(c lcm.Chord, fn func()) {
+	m.chords[c] = fn
+}
+
+// Confirm shows a Yes/No prompt with msg and blocks until the user has
+// made a choice. Returns false if the context is canceled or the
+// display goes idle before a choice was made.
 func (m *Monitor) Confirm(ctx context.Context, msg string) bool {
-	m.menu.confirm()
-	return true
+	return m.menu.confirm(ctx, msg)
 }
 
+// Send the message to the display, the display is woken first if it
+// was turned off due to inactivity (see Wake).
 func (m *Monitor) Send(msg lcm.Message) error {
-	select {
-	case m.actC <- struct{}{}:
-	default:
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.wake()
+	err := m.lcm.Send(msg)
+	m.lastSend = time.Now()
+	m.activity()
+	return err
+}
+
+// Wake turns the display on if it was turned off due to inactivity and
+// resets the inactivity timer.
+func (m *Monitor) Wake() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.wake()
+	m.activity()
+}
+
+// wake turns the display on, m.mu must be held.
+func (m *Monitor) wake() {
+	if atomic.CompareAndSwapInt32(&m.off, 1, 0) {
+		m.send(lcm.DisplayOn)
+	}
+}
+
+// DisplayIsOff reports whether the display has been turned off due to
+// inactivity.
+func (m *Monitor) DisplayIsOff() bool {
+	return atomic.LoadInt32(&m.off) == 1
+}
+
+// activity resets the inactivity timer. The display is on after any
+// activity, it is woken either by a button press or by Send.
+func (m *Monitor) activity() {
+	atomic.StoreInt32(&m.off, 0)
+	m.act.Touch()
+}
+
+// Blink the text on the display line until the context is canceled.
+func (m *Monitor) Blink(ctx context.Context, line lcm.DisplayLine, text string, interval time.Duration) error {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	on := true
+	for {
+		b, err := lcm.Blink(line, text, on)
+		if err != nil {
+			return err
+		}
+		err = m.Send(b)
+		if err != nil {
+			return err
+		}
+		on = !on
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
 	}
-	return m.lcm.Send(msg)
 }
 
 func (m *Monitor) idle() {
@@ -80,33 +281,44 @@ func (m *Monitor) idle() {
 		}
 	}()
 
-	<-m.actC
-
 	for {
 		select {
 		case <-m.ctx.Done():
 			return
-		case <-m.actC:
-		case <-time.After(activityTimeout):
-			m.off = true
+		case <-m.act.Idle():
+			m.mu.Lock()
+			if time.Since(m.lastSend) < m.sendGrace() {
+				// A send raced with the timeout.
+				m.mu.Unlock()
+				continue
+			}
+			atomic.StoreInt32(&m.off, 1)
 			m.send(lcm.DisplayOff)
 			m.send(lcm.DisplayStatus)
+			m.mu.Unlock()
+
 			m.menu.close()
-			<-m.actC
-			m.off = false
 		}
 	}
 }
 
+// sendGrace returns the grace period after a send, it never exceeds the
+// idle timeout.
+func (m *Monitor) sendGrace() time.Duration {
+	if m.idleTimeout < sendGrace {
+		return m.idleTimeout
+	}
+	return sendGrace
+}
+
 func (m *Monitor) recv() {
+	defer close(m.recvDone)
+
 	for {
-		select {
-		case <-m.ctx.Done():
+		b, err := m.lcm.RecvContext(m.ctx)
+		if err != nil {
 			return
-		default:
 		}
-
-		b := m.lcm.Recv()
 		switch b.Type() {
 		case lcm.Command:
 			switch b.Function() {
@@ -119,36 +331,62 @@ func (m *Monitor) recv() {
 				switch btn {
 				case lcm.Up:
 					kp = uinput.KeyUp
-					action = m.menu.up
+					action = func() {
+						if !m.page(-1) {
+							m.info.reset()
+							m.menu.up()
+						}
+					}
 				case lcm.Down:
 					kp = uinput.KeyDown
-					action = m.menu.down
+					action = func() {
+						if !m.page(1) {
+							m.info.reset()
+							m.menu.down()
+						}
+					}
 				case lcm.Back:
 					kp = uinput.KeyBack
-					action = m.menu.back
+					action = func() {
+						m.info.reset()
+						m.menu.back()
+					}
 				case lcm.Enter:
 					kp = uinput.KeyEnter
-					action = m.menu.enter
+					action = func() {
+						m.info.reset()
+						m.menu.enter()
+					}
 				}
 
 				if m.kbd != nil && kp > 0 {
 					m.kbd.KeyPress(kp)
 				}
+				if m.leaveSecondary() {
+					action = func() {}
+				}
 				action()
+				if m.menuTimeout > 0 {
+					m.menuTimer.Reset(m.menuTimeout)
+				}
+
+				if c, ok := m.chord.Press(btn, time.Now()); ok {
+					if fn := m.chords[c]; fn != nil {
+						log.Printf("Chord: %s", c)
+						fn()
+					}
+				}
 
 				// Screen is implicitly woken on button
 				// press, so reset inactivity timer.
-				select {
-				case m.actC <- struct{}{}:
-				default:
-				}
+				m.activity()
 
 			case lcm.Fversion:
 				ver := b.Value()
 				log.Printf("Detected LCM MCU version %d.%d.%d", ver[0], ver[1], ver[2])
 
 			default:
-				log.Printf("Unhandled command: %#x", b.Function())
+				log.Printf("Unhandled command: %v", b.Function())
 			}
 
 		case lcm.Reply:
@@ -166,13 +404,27 @@ func (m *Monitor) send(b lcm.Message) {
 	}
 }
 
-func (m *Monitor) PowerCycle() {
-	if m.p != nil {
-		<-m.p.Cycle()
+// PowerCycle power cycles the display and waits for it to settle (or
+// the monitor to close), it is a no-op when power cycling is disabled.
+func (m *Monitor) PowerCycle() error {
+	if m.p == nil {
+		return nil
 	}
+	err := m.p.CycleContext(m.ctx)
+	if err != nil {
+		return fmt.Errorf("power cycle: %w", err)
+	}
+	return nil
 }
 
+// Close the monitor, waits for button presses to stop being handled.
 func (m *Monitor) Close() error {
 	m.cancel()
+	<-m.recvDone
+	m.menuTimer.Stop()
+	m.act.Stop()
+	if m.secAct != nil {
+		m.secAct.Stop()
+	}
 	return nil
 }
@@ -0,0 +1,261 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mafredri/lcm"
+	"github.com/mafredri/lcm/lcmtest"
+)
+
+func testMonitor(t *testing.T, opts ...Option) (*Monitor, *lcmtest.FakeMCU) {
+	t.Helper()
+	f := lcmtest.NewFakeMCU()
+	l := lcm.OpenConn(f)
+	m := New(context.Background(), "test", l, nil, opts...)
+	t.Cleanup(func() {
+		m.Close()
+		l.Close()
+	})
+	return m, f
+}
+
+func received(f *lcmtest.FakeMCU, msg lcm.Message) bool {
+	for _, r := range f.Received() {
+		if bytes.Equal(r, msg) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestMonitor_idleTimeout(t *testing.T) {
+	m, f := testMonitor(t, WithIdleTimeout(20*time.Millisecond))
+	if err := m.Send(lcm.DisplayOn); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for !m.DisplayIsOff() || !received(f, lcm.DisplayOff) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for display to turn off, DisplayIsOff() = %v", m.DisplayIsOff())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestMonitor_idleTimeoutDisabled(t *testing.T) {
+	m, f := testMonitor(t, WithIdleTimeout(0))
+	if err := m.Send(lcm.DisplayOn); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if m.DisplayIsOff() {
+		t.Error("DisplayIsOff() = true, want false")
+	}
+	if received(f, lcm.DisplayOff) {
+		t.Error("DisplayOff sent, want always on")
+	}
+}
+
+func TestMonitor_wake(t *testing.T) {
+	tests := []struct {
+		name string
+		wake func(m *Monitor) error
+		want []lcm.Message
+	}{
+		{"Send", func(m *Monitor) error { return m.Send(lcm.ClearDisplay) }, []lcm.Message{lcm.DisplayOn, lcm.ClearDisplay}},
+		{"Wake", func(m *Monitor) error { m.Wake(); return nil }, []lcm.Message{lcm.DisplayOn}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, f := testMonitor(t, WithIdleTimeout(20*time.Millisecond))
+			m.Wake() // Start the inactivity timer.
+
+			deadline := time.Now().Add(time.Second)
+			for !m.DisplayIsOff() || !received(f, lcm.DisplayStatus) {
+				if time.Now().After(deadline) {
+					t.Fatal("timed out waiting for display to turn off")
+				}
+				time.Sleep(time.Millisecond)
+			}
+			n := len(f.Received())
+
+			if err := tt.wake(m); err != nil {
+				t.Fatal(err)
+			}
+			if m.DisplayIsOff() {
+				t.Error("DisplayIsOff() = true, want false")
+			}
+
+			got := f.Received()[n:]
+			if len(got) != len(tt.want) {
+				t.Fatalf("received %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !bytes.Equal(got[i], tt.want[i]) {
+					t.Errorf("received[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMonitor_RunCommandIdle(t *testing.T) {
+	m, f := testMonitor(t, WithIdleTimeout(20*time.Millisecond))
+	if err := m.Send(lcm.DisplayOn); err != nil {
+		t.Fatal(err)
+	}
+
+	// Cancel the command when the display goes idle, like the menu.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		for !m.DisplayIsOff() || !received(f, lcm.DisplayOff) {
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+	}()
+
+	// The spinner must not keep the display awake.
+	err := m.RunCommand(5*time.Second, "sleep", "5")(ctx)
+	if err == nil {
+		t.Error("RunCommand() error = nil, want canceled")
+	}
+
+	if !m.DisplayIsOff() {
+		t.Error("DisplayIsOff() = false, want true")
+	}
+	msgs := f.Received()
+	for i := len(msgs) - 1; i >= 0 && !bytes.Equal(msgs[i], lcm.DisplayOff); i-- {
+		if bytes.Equal(msgs[i], lcm.DisplayOn) {
+			t.Fatal("display turned on after idle")
+		}
+	}
+}
+
+// lastLine returns the last text written to the display line.
+func lastLine(f *lcmtest.FakeMCU, line lcm.DisplayLine) string {
+	var text string
+	for _, r := range f.Received() {
+		if r.Function() == lcm.Ftext && lcm.DisplayLine(r[3]) == line {
+			text = strings.TrimRight(string(r[5:]), " ")
+		}
+	}
+	return text
+}
+
+func TestMonitor_SetSecondaryHome(t *testing.T) {
+	m, f := testMonitor(t, WithIdleTimeout(0))
+	m.SetHome(m.TextScreen(func(context.Context) (string, string, error) { return "home", "", nil }))
+	m.SetMenu(MenuItem{Name: "Menu", SubMenu: []MenuItem{{Name: "Item"}}})
+	m.SetSecondaryHome(20*time.Millisecond, m.TextScreen(func(context.Context) (string, string, error) { return "clock", "", nil }))
+
+	waitLine := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for lastLine(f, lcm.DisplayTop) != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %q, got %q", want, lastLine(f, lcm.DisplayTop))
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	waitLine("clock")
+	if m.DisplayIsOff() {
+		t.Error("DisplayIsOff() = true, want false")
+	}
+
+	// The button press only returns to home, the menu is not opened.
+	f.PushButton(lcm.Enter)
+	waitLine("home")
+	time.Sleep(5 * time.Millisecond)
+	if got := lastLine(f, lcm.DisplayTop); got != "home" {
+		t.Errorf("top line = %q after button press, want %q", got, "home")
+	}
+
+	waitLine("clock")
+}
+
+// TestMonitor_buttonRepeat checks that a button command sent twice for
+// a single press only navigates the menu once.
+func TestMonitor_buttonRepeat(t *testing.T) {
+	m, f := testMonitor(t, WithIdleTimeout(0))
+	m.SetMenu(testMenuItem())
+
+	waitTop := func(skip string) string {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for {
+			if top := lastLine(f, lcm.DisplayTop); top != "" && top != skip {
+				return top
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for top line other than %q", skip)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	f.PushButton(lcm.Enter)
+	if got := waitTop(""); got != "Main" {
+		t.Fatalf("top line = %q, want %q", got, "Main")
+	}
+	f.PushButton(lcm.Down)
+	f.PushButton(lcm.Down) // Repeated command.
+	f.PushButton(lcm.Enter)
+	if got := waitTop("Main"); got != "B" {
+		t.Errorf("top line = %q, want %q", got, "B")
+	}
+}
+
+// TestMonitor_concurrent drives button presses, sends and menu changes
+// concurrently, run with -race.
+func TestMonitor_concurrent(t *testing.T) {
+	m, f := testMonitor(t, WithIdleTimeout(0), WithMenuTimeout(time.Millisecond))
+	m.SetHome(m.TextScreen(func(context.Context) (string, string, error) { return "home", "", nil }))
+	m.SetMenu(testMenuItem())
+
+	var wg sync.WaitGroup
+	run := func(fn func(i int)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				fn(i)
+			}
+		}()
+	}
+	buttons := []lcm.Button{lcm.Enter, lcm.Down, lcm.Enter, lcm.Up, lcm.Back, lcm.Back}
+	run(func(i int) { f.PushButton(buttons[i%len(buttons)]) })
+	run(func(i int) { m.Send(lcm.DisplayStatus) })
+	run(func(i int) {
+		if i%10 == 0 {
+			m.SetMenu(testMenuItem())
+		}
+		m.Wake()
+	})
+	wg.Wait()
+}
+
+func TestMonitor_recvCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	l := lcm.OpenConn(lcmtest.NewFakeMCU())
+	defer l.Close()
+	m := New(ctx, "test", l, nil)
+	defer m.Close()
+
+	// No messages are pending, recv must not wait for one.
+	cancel()
+	select {
+	case <-m.recvDone:
+	case <-time.After(time.Second):
+		t.Fatal("recv did not return after the context was canceled")
+	}
+}
@@ -0,0 +1,156 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/net"
+
+	"github.com/mafredri/lcm"
+)
+
+// DefaultNetRateInterval is the default sampling interval for NetRate.
+const DefaultNetRateInterval = 2 * time.Second
+
+// ErrNoInterface is returned when no primary network interface is found.
+var ErrNoInterface = errors.New("no network interface found")
+
+// PrimaryInterface returns the primary network interface, loopback,
+// bridge and container interfaces are ignored.
+func PrimaryInterface(ctx context.Context) (net.InterfaceStat, error) {
+	netif, err := net.InterfacesWithContext(ctx)
+	if err != nil {
+		return net.InterfaceStat{}, err
+	}
+	var primary net.InterfaceStat
+	var ok bool
+	for _, i := range netif {
+		if i.Name == "lo" || strings.HasPrefix(i.Name, "br-") || strings.HasPrefix(i.Name, "docker") || strings.HasPrefix(i.Name, "veth") {
+			continue
+		}
+		if len(i.Addrs) == 0 {
+			continue
+		}
+		primary, ok = i, true
+	}
+	if !ok {
+		return primary, ErrNoInterface
+	}
+	return primary, nil
+}
+
+// netRate keeps track of the transfer rates of the primary interface.
+type netRate struct {
+	mu     sync.Mutex
+	name   string
+	prev   net.IOCountersStat
+	prevT  time.Time
+	rx, tx float64 // Bytes per second.
+	err    error
+}
+
+// NetRate returns a TextFunc that shows the download and upload rate
+// of the primary network interface (see PrimaryInterface), e.g.:
+//
+//	eth0
+//	D 12.3M U 1.1M
+//
+// The rates are prefixed by lcm.Sym.ArrowDown and lcm.Sym.ArrowUp when
+// the display has arrow glyphs, otherwise by D and U. The counters are
+// sampled every interval until the monitor is closed.
+func (m *Monitor) NetRate(interval time.Duration) TextFunc {
+	if interval <= 0 {
+		interval = DefaultNetRateInterval
+	}
+	r := &netRate{}
+	go r.sample(m.ctx, interval)
+
+	return func(ctx context.Context) (top, bottom string, err error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		if r.err != nil {
+			return "", "", r.err
+		}
+		down, up := rateArrows()
+		bottom = fmt.Sprintf("%c %s %c %s", down, humanBytes(uint64(r.rx)), up, humanBytes(uint64(r.tx)))
+		return r.name, bottom, nil
+	}
+}
+
+// rateArrows returns the download and upload prefixes, the ASCII
+// fallbacks of lcm.Sym (v and ^) are replaced by D and U.
+func rateArrows() (down, up byte) {
+	down, up = 'D', 'U'
+	if _, ok := lcm.CharSetV012.Supports('↓'); ok {
+		down = lcm.Sym.ArrowDown
+	}
+	if _, ok := lcm.CharSetV012.Supports('↑'); ok {
+		up = lcm.Sym.ArrowUp
+	}
+	return down, up
+}
+
+func (r *netRate) sample(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		err := r.update(ctx, time.Now())
+		if err != nil && ctx.Err() == nil {
+			log.Printf("netrate: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (r *netRate) update(ctx context.Context, now time.Time) error {
+	iface, err := PrimaryInterface(ctx)
+	if err == nil {
+		var counters []net.IOCountersStat
+		counters, err = net.IOCountersWithContext(ctx, true)
+		if err == nil {
+			err = r.add(iface.Name, counters, now)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.err = err
+	return err
+}
+
+func (r *netRate) add(name string, counters []net.IOCountersStat, now time.Time) error {
+	for _, c := range counters {
+		if c.Name != name {
+			continue
+		}
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		// Start over when the interface changes or the counters
+		// are reset (e.g. the interface was recreated).
+		if r.name != name || c.BytesRecv < r.prev.BytesRecv || c.BytesSent < r.prev.BytesSent {
+			r.name = name
+			r.rx, r.tx = 0, 0
+		} else if d := now.Sub(r.prevT).Seconds(); d > 0 {
+			r.rx = float64(c.BytesRecv-r.prev.BytesRecv) / d
+			r.tx = float64(c.BytesSent-r.prev.BytesSent) / d
+		}
+		r.prev = c
+		r.prevT = now
+		return nil
+	}
+	return fmt.Errorf("no counters for %s", name)
+}
@@ -0,0 +1,139 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/host"
+	"github.com/shirou/gopsutil/v3/load"
+
+	"github.com/mafredri/lcm"
+)
+
+// TextFunc returns the text for the top and bottom line of the display.
+type TextFunc func(ctx context.Context) (top, bottom string, err error)
+
+// TextScreen returns an info screen (see AddInfoScreen) that shows the
+// text returned by fn. Text that is too long is truncated.
+//
+// Drawing the screen does not count as activity, the display is allowed
+// to go idle while info screens are rotated.
+func (m *Monitor) TextScreen(fn TextFunc) UpdateDisplayFunc {
+	return func(ctx context.Context) error {
+		top, bottom, err := fn(ctx)
+		if err != nil {
+			return err
+		}
+		return m.lcm.SetLines(
+			lcm.Truncate(lcm.Transliterate(top), 16),
+			lcm.Truncate(lcm.Transliterate(bottom), 16),
+		)
+	}
+}
+
+// SystemStats returns the load average and the CPU temperature, e.g.:
+//
+//	Load 0.42 0.31
+//	Temp 48C
+//
+// The temperature is omitted when no CPU sensor is found.
+func SystemStats(ctx context.Context) (top, bottom string, err error) {
+	avg, err := load.AvgWithContext(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	top = fmt.Sprintf("Load %.2f %.2f", avg.Load1, avg.Load5)
+
+	if temp, ok := cpuTemperature(ctx); ok {
+		bottom = fmt.Sprintf("Temp %.0fC", temp)
+	}
+	return top, bottom, nil
+}
+
+// cpuTemperature returns the highest CPU temperature reported by the
+// sensors, if any.
+func cpuTemperature(ctx context.Context) (float64, bool) {
+	// Sensors may return partial results along with warnings, so
+	// the error is ignored.
+	temps, _ := host.SensorsTemperaturesWithContext(ctx)
+
+	var max float64
+	var ok bool
+	for _, t := range temps {
+		key := strings.ToLower(t.SensorKey)
+		if !strings.Contains(key, "coretemp") && !strings.Contains(key, "k10temp") && !strings.Contains(key, "cpu") {
+			continue
+		}
+		if t.Temperature <= 0 {
+			continue
+		}
+		if !ok || t.Temperature > max {
+			max = t.Temperature
+			ok = true
+		}
+	}
+	return max, ok
+}
+
+// DiskUsage returns the usage of the volume mounted at mountpoint, e.g.:
+//
+//	/volume1 73%
+//	2.1T/2.9T
+func DiskUsage(ctx context.Context, mountpoint string) (top, bottom string, err error) {
+	u, err := disk.UsageWithContext(ctx, mountpoint)
+	if err != nil {
+		return "", "", err
+	}
+	pct := fmt.Sprintf("%.0f%%", u.UsedPercent)
+	top = fmt.Sprintf("%s %s", lcm.Truncate(mountpoint, 16-len(pct)-1), pct)
+	bottom = fmt.Sprintf("%s/%s", humanBytes(u.Used), humanBytes(u.Total))
+	return top, bottom, nil
+}
+
+// DiskUsageScreens returns a TextFunc that shows the usage of the next
+// mountpoint each time it is called (see DiskUsage). Mountpoints that
+// return an error are skipped.
+func DiskUsageScreens(mountpoints ...string) TextFunc {
+	var mu sync.Mutex
+	var next int
+	return func(ctx context.Context) (top, bottom string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if len(mountpoints) == 0 {
+			return "", "", fmt.Errorf("disk usage: no mountpoints")
+		}
+		for range mountpoints {
+			mp := mountpoints[next]
+			next = (next + 1) % len(mountpoints)
+
+			top, bottom, err = DiskUsage(ctx, mp)
+			if err == nil {
+				return top, bottom, nil
+			}
+		}
+		return "", "", fmt.Errorf("disk usage: %w", err)
+	}
+}
+
+// humanBytes formats n using binary units, e.g. 2.1T.
+func humanBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := uint64(unit), 0
+	for v := n / unit; v >= unit && exp < len("KMGTPE")-1; v /= unit {
+		div *= unit
+		exp++
+	}
+	v := float64(n) / float64(div)
+	if v >= 100 {
+		// Keep the width down, e.g. 123G.
+		return fmt.Sprintf("%.0f%c", v, "KMGTPE"[exp])
+	}
+	return fmt.Sprintf("%.1f%c", v, "KMGTPE"[exp])
+}
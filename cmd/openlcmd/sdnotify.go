@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/mafredri/lcm"
+)
+
+// sdNotify sends the state to systemd (see sd_notify(3)), it is a no-op
+// when not running under systemd (NOTIFY_SOCKET is unset).
+func sdNotify(state string) error {
+	name := os.Getenv("NOTIFY_SOCKET")
+	if name == "" {
+		return nil
+	}
+	if name[0] == '@' {
+		name = "\x00" + name[1:] // Abstract socket.
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: name, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(state))
+	return err
+}
+
+// sdWatchdogInterval returns the watchdog interval configured by
+// systemd (WatchdogSec), zero if the watchdog is disabled.
+func sdWatchdogInterval() time.Duration {
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond
+}
+
+// sdWatchdog pings the systemd watchdog for as long as communication
+// with the display succeeds. If the serial handler gets stuck, pings
+// stop and systemd restarts the service.
+func sdWatchdog(ctx context.Context, m *lcm.LCM, interval time.Duration) {
+	t := time.NewTicker(interval / 2)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		// DisplayStatus is harmless, lcmd sends it regularly.
+		err := m.Send(lcm.DisplayStatus)
+		if err != nil {
+			log.Printf("watchdog: display not responding: %v", err)
+			continue
+		}
+		err = sdNotify("WATCHDOG=1")
+		if err != nil {
+			log.Printf("watchdog: notify failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package lcm
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WithCoalescing skips text updates (see SetDisplay) that are identical
+// to the text last sent for the same line, reducing serial traffic when
+// e.g. a screen is redrawn periodically. Skipped messages are counted in
+// Stats.
+//
+// When minInterval is non-zero, sends are spaced at least minInterval
+// apart.
+//
+// Messages that change the display contents (ClearDisplay,
+// ClearDisplayPrefix, DisplayOn and DisplayOff) reset the state, the
+// next text update is always sent. Other messages, e.g. DisplayStatus
+// sent by WithHeartbeat, leave the state intact.
+func WithCoalescing(minInterval time.Duration) OpenOption {
+	return func(o *openOptions) {
+		o.coalesce = true
+		o.minInterval = minInterval
+	}
+}
+
+// coalescer keeps track of the text on the display.
+type coalescer struct {
+	skipped uint64 // Accessed atomically, first for alignment.
+
+	mu       sync.Mutex
+	lines    [2]Message // Last text message per line.
+	nextSend time.Time
+}
+
+// textLine returns the line msg writes text to.
+func textLine(msg Message) (DisplayLine, bool) {
+	if msg.Function() != Ftext || len(msg) < 4 {
+		return 0, false
+	}
+	line := DisplayLine(msg[3])
+	return line, line == DisplayTop || line == DisplayBottom
+}
+
+// changesDisplay reports if messages with function fn (other than text
+// and character updates) change the display contents.
+func changesDisplay(fn Function) bool {
+	switch fn {
+	case Fon, Fclear, Fclear2:
+		return true
+	}
+	return false
+}
+
+// filter returns the messages that would change the display, along
+// with their index in msgs.
+func (c *coalescer) filter(msgs []Message) (send []Message, index []int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, msg := range msgs {
+		if line, ok := textLine(msg); ok && bytes.Equal(c.lines[line], msg) {
+			atomic.AddUint64(&c.skipped, 1)
+			continue
+		}
+		send = append(send, msg)
+		index = append(index, i)
+	}
+	return send, index
+}
+
+// wait reserves the next send slot and sleeps until it's due.
+func (c *coalescer) wait(minInterval time.Duration) {
+	if minInterval <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	now := time.Now()
+	if c.nextSend.Before(now) {
+		c.nextSend = now
+	}
+	d := c.nextSend.Sub(now)
+	c.nextSend = c.nextSend.Add(minInterval)
+	c.mu.Unlock()
+
+	time.Sleep(d)
+}
+
+// update the state after msgs have been sent, failed messages (and those
+// after them) are forgotten since their effect is unknown.
+func (c *coalescer) update(msgs []Message, failed int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, msg := range msgs {
+		line, ok := textLine(msg)
+		switch {
+		case !ok && msg.Function() == Fchar && len(msg) > 3 && msg[3] <= byte(DisplayBottom):
+			c.lines[msg[3]] = nil
+		case !ok && changesDisplay(msg.Function()):
+			c.reset()
+		case !ok:
+			// E.g. DisplayStatus, the contents are unchanged.
+		case i >= failed:
+			c.lines[line] = nil
+		default:
+			c.lines[line] = msg
+		}
+	}
+}
+
+// forget the display state.
+func (c *coalescer) forget() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reset()
+}
+
+// reset forgets the display state, c.mu must be held.
+func (c *coalescer) reset() {
+	c.lines = [2]Message{}
+}
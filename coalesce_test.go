@@ -0,0 +1,52 @@
+package lcm_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mafredri/lcm"
+)
+
+func TestLCM_Coalescing(t *testing.T) {
+	m, f := openFake(t, nil, lcm.WithCoalescing(0))
+
+	steps := []struct {
+		name     string
+		send     func() error
+		wantSent int
+	}{
+		{"First", func() error { return m.SetLines("Hello", "World") }, 2},
+		{"Identical", func() error { return m.SetLines("Hello", "World") }, 0},
+		{"Bottom changed", func() error { return m.SetLines("Hello", "There") }, 1},
+		{"Status", func() error { return m.Send(lcm.DisplayStatus) }, 1},
+		{"After status", func() error { return m.SetLines("Hello", "There") }, 0},
+		{"Clear resets", func() error { return m.Send(lcm.ClearDisplay) }, 1},
+		{"After clear", func() error { return m.SetLines("Hello", "There") }, 2},
+	}
+	for _, st := range steps {
+		n := len(f.Received())
+		if err := st.send(); err != nil {
+			t.Fatalf("%s: %v", st.name, err)
+		}
+		if got := len(f.Received()) - n; got != st.wantSent {
+			t.Errorf("%s: sent %d messages, want %d", st.name, got, st.wantSent)
+		}
+	}
+	if got := m.Stats().Coalesced; got != 5 {
+		t.Errorf("Stats().Coalesced = %d, want 5", got)
+	}
+}
+
+func TestLCM_CoalescingMinInterval(t *testing.T) {
+	m, _ := openFake(t, nil, lcm.WithCoalescing(20*time.Millisecond))
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := m.Send(lcm.ClearDisplay); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d := time.Since(start); d < 40*time.Millisecond {
+		t.Errorf("3 sends took %v, want at least 40ms", d)
+	}
+}
@@ -0,0 +1,80 @@
+package lcm_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mafredri/lcm"
+)
+
+func TestLCM_WithButtonDebounce(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []lcm.OpenOption
+		pause time.Duration // Between the repeated presses.
+		want  []lcm.Button
+	}{
+		{"Default", nil, 0, []lcm.Button{lcm.Enter, lcm.Up}},
+		{"Disabled", []lcm.OpenOption{lcm.WithButtonDebounce(0)}, 0, []lcm.Button{lcm.Enter, lcm.Enter, lcm.Up}},
+		{"Repeat", []lcm.OpenOption{lcm.WithButtonDebounce(lcm.DefaultButtonDebounce)}, 0, []lcm.Button{lcm.Enter, lcm.Up}},
+		{"After window", []lcm.OpenOption{lcm.WithButtonDebounce(time.Millisecond)}, 20 * time.Millisecond, []lcm.Button{lcm.Enter, lcm.Enter, lcm.Up}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, f := openFake(t, nil, tt.opts...)
+			buttons := m.Buttons()
+
+			var got []lcm.Button
+			next := func() lcm.Button {
+				select {
+				case b := <-buttons:
+					got = append(got, b)
+					return b
+				case <-time.After(time.Second):
+					t.Fatalf("timed out waiting for buttons, got %v", got)
+					return 0
+				}
+			}
+
+			f.PushButton(lcm.Enter)
+			next()
+			// The window starts when the first press is forwarded.
+			time.Sleep(tt.pause)
+			f.PushButton(lcm.Enter)
+			f.PushButton(lcm.Up)
+			for next() != lcm.Up {
+			}
+
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("buttons = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLCM_WithButtonDebounce_Recv(t *testing.T) {
+	m, f := openFake(t, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	next := func() lcm.Button {
+		t.Helper()
+		msg, err := m.RecvContext(ctx)
+		if err != nil {
+			t.Fatalf("RecvContext() error = %v", err)
+		}
+		return lcm.Button(msg.Value()[0])
+	}
+
+	f.PushButton(lcm.Enter)
+	f.PushButton(lcm.Enter)
+	f.PushButton(lcm.Up)
+	if got := next(); got != lcm.Enter {
+		t.Errorf("first button = %v, want %v", got, lcm.Enter)
+	}
+	if got := next(); got != lcm.Up {
+		t.Errorf("second button = %v, want %v", got, lcm.Up)
+	}
+}
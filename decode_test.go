@@ -0,0 +1,68 @@
+package lcm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMessage_Decode(t *testing.T) {
+	tests := []struct {
+		name    string
+		m       Message
+		want    DecodedMessage
+		wantStr string
+		wantErr bool
+	}{
+		{
+			name:    "Button",
+			m:       Message{0xf0, 0x01, 0x80, 0x02},
+			want:    DecodedMessage{Type: Command, Function: Fbutton, Data: []byte{0x02}, Button: Down},
+			wantStr: "Command len=1 Fbutton data=[0x02]",
+		},
+		{
+			name:    "Version",
+			m:       Message{0xf0, 0x03, 0x13, 0x00, 0x01, 0x02},
+			want:    DecodedMessage{Type: Command, Function: Fversion, Data: []byte{0x00, 0x01, 0x02}},
+			wantStr: "Command len=3 Fversion data=[0x00 0x01 0x02]",
+		},
+		{
+			name:    "Text reply",
+			m:       Message{0xf1, 0x01, 0x27, 0x00},
+			want:    DecodedMessage{Type: Reply, Function: Ftext, Data: []byte{0x00}},
+			wantStr: "Reply len=1 Ftext data=[0x00]",
+		},
+		{
+			name:    "Too short",
+			m:       Message{0xf1, 0x01},
+			wantStr: "Message(0xf101)",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.m.Decode()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Message.Decode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if diff := cmp.Diff(tt.want, got); err == nil && diff != "" {
+				t.Errorf("Message.Decode() (-want +got)\n%s", diff)
+			}
+			if got := tt.m.String(); got != tt.wantStr {
+				t.Errorf("Message.String() = %q, want %q", got, tt.wantStr)
+			}
+		})
+	}
+}
+
+func TestMessage_Format(t *testing.T) {
+	m := Message{0xf0, 0x01, 0x11, 0x01}
+	if got, want := fmt.Sprintf("%#x", m), "0xf0011101"; got != want {
+		t.Errorf("Sprintf(%%#x) = %s, want %s", got, want)
+	}
+	if got, want := fmt.Sprintf("%v", m), "Command len=1 Fon data=[0x01]"; got != want {
+		t.Errorf("Sprintf(%%v) = %s, want %s", got, want)
+	}
+}
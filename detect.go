@@ -0,0 +1,121 @@
+package lcm
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pkg/term"
+)
+
+const (
+	// detectTimeout defines how long we wait for the version reply
+	// on each candidate port. The MCU takes 200+ms to respond to
+	// RequestVersion, see its documentation.
+	detectTimeout = 750 * time.Millisecond
+	// detectReadTimeout is the read timeout for the serial port
+	// while probing, it allows us to give up on silent ports.
+	detectReadTimeout = 50 * time.Millisecond
+)
+
+// detectCandidates are the serial ports probed by DetectTTY, in order.
+var detectCandidates = []string{
+	"/dev/ttyS0",
+	"/dev/ttyS1",
+	"/dev/ttyS2",
+	"/dev/ttyS3",
+}
+
+var errDetectTimeout = errors.New("timed out waiting for version")
+
+// DetectTTY probes the candidate serial ports (ttyS0..ttyS3) for the
+// LCM by sending RequestVersion and waiting for a valid version frame.
+// The first port that replies is returned.
+//
+// Note that probing writes to each candidate port, only use this when
+// the other serial ports are known to be unused (or able to handle
+// garbage).
+func DetectTTY() (string, error) {
+	for _, tty := range detectCandidates {
+		err := probeTTY(tty)
+		if err == nil {
+			return tty, nil
+		}
+	}
+	return "", fmt.Errorf("lcm not found on %v", detectCandidates)
+}
+
+func probeTTY(tty string) error {
+	s, err := term.Open(tty, term.Speed(115200), term.RawMode, term.ReadTimeout(detectReadTimeout))
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	err = s.Flush()
+	if err != nil {
+		return err
+	}
+
+	data := make([]byte, len(RequestVersion), len(RequestVersion)+1)
+	copy(data, RequestVersion)
+	data = append(data, Checksum(data))
+
+	_, err = s.Write(data)
+	if err != nil {
+		return err
+	}
+
+	return waitVersion(s, detectTimeout)
+}
+
+// waitVersion reads from r until a version frame is received, garbage
+// and other frames are skipped. Gives up with errDetectTimeout after
+// timeout.
+func waitVersion(r io.Reader, timeout time.Duration) error {
+	var parseErr parsingError
+	rr := &resyncReader{r: &deadlineReader{r: r, deadline: time.Now().Add(timeout)}}
+	raw := &recvMessage{}
+	for {
+		raw.Reset()
+		err := copyBytes(raw, rr)
+		if err != nil {
+			if errors.As(err, &parseErr) {
+				rr.resync(raw.Bytes())
+				continue
+			}
+			return err
+		}
+
+		b := Message(raw.Bytes())
+		if b.Type() == Command && b.Function() == Fversion && len(b.Value()) == 3 {
+			return nil
+		}
+	}
+}
+
+// deadlineReader reads one byte at a time until the deadline has been
+// reached. It relies on the underlying reader having a read timeout.
+type deadlineReader struct {
+	r        io.Reader
+	deadline time.Time
+	buf      [1]byte
+}
+
+var _ io.ByteReader = (*deadlineReader)(nil)
+
+func (r *deadlineReader) ReadByte() (byte, error) {
+	for {
+		if time.Now().After(r.deadline) {
+			return 0, errDetectTimeout
+		}
+		n, err := r.r.Read(r.buf[:])
+		if n == 1 {
+			return r.buf[0], nil
+		}
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+	}
+}
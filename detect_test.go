@@ -0,0 +1,36 @@
+package lcm
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_waitVersion(t *testing.T) {
+	frame := func(m Message) []byte {
+		return append(append([]byte(nil), m...), Checksum(m))
+	}
+	version := frame(Message{0xf0, 0x03, 0x13, 0x00, 0x01, 0x02})
+	button := frame(Message{0xf0, 0x01, 0x80, 0x01})
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{"Version", version, nil},
+		{"Garbage before version", append([]byte{0x00, 0xf0, 0xff, 0x13}, version...), nil},
+		{"Other frame before version", append(button, version...), nil},
+		{"Silent", nil, errDetectTimeout},
+		{"No version", button, errDetectTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := waitVersion(bytes.NewReader(tt.data), 10*time.Millisecond)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("waitVersion() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package lcm
+
+// Tickets returns the number of sends that have entered the queue.
+func Tickets(m *LCM) uint64 {
+	m.queueMu.Lock()
+	defer m.queueMu.Unlock()
+	return m.ticket
+}
+
+// Queued returns the number of sends that have been queued for writing
+// (or gave up due to close).
+func Queued(m *LCM) uint64 {
+	m.queueMu.Lock()
+	defer m.queueMu.Unlock()
+	return m.serving
+}
@@ -0,0 +1,40 @@
+package lcm_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mafredri/lcm"
+	"github.com/mafredri/lcm/lcmtest"
+)
+
+// openFake opens an LCM connected to a new FakeMCU replying with reply
+// (lcmtest.ReplyOk when nil). The LCM is closed when the test ends.
+func openFake(t *testing.T, reply lcmtest.ReplyFunc, opts ...lcm.OpenOption) (*lcm.LCM, *lcmtest.FakeMCU) {
+	t.Helper()
+	f := lcmtest.NewFakeMCU()
+	if reply != nil {
+		f.SetReplyFunc(reply)
+	}
+	m := lcm.OpenConn(f, opts...)
+	t.Cleanup(func() { m.Close() })
+	return m, f
+}
+
+// waitReceived waits for the FakeMCU to have received n frames.
+func waitReceived(t *testing.T, f *lcmtest.FakeMCU, n int) []lcm.Message {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	msgs, err := f.WaitReceived(ctx, n)
+	if err != nil {
+		t.Fatalf("waiting for %d frames: %v", n, err)
+	}
+	return msgs
+}
+
+// withChecksum returns the frame of msg, as written to the display.
+func withChecksum(msg lcm.Message) []byte {
+	return append(append([]byte(nil), msg...), lcm.Checksum(msg))
+}
@@ -0,0 +1,45 @@
+package lcm_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mafredri/lcm"
+	"github.com/mafredri/lcm/lcmtest"
+)
+
+func TestLCM_FlushMCU(t *testing.T) {
+	stuck := true
+	m, f := openFake(t, func(msg lcm.Message) []byte {
+		if msg.Function() == 0x00 { // Flush.
+			stuck = false
+		}
+		if stuck {
+			return lcmtest.ReplyError(msg)
+		}
+		return lcmtest.ReplyOk(msg)
+	})
+
+	if err := m.FlushMCU(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Send(lcm.DisplayOn); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []lcm.Function
+	for _, msg := range f.Received() {
+		got = append(got, msg.Function())
+	}
+	// The flush is sent twice, followed by a single (successful) write.
+	want := []lcm.Function{0x00, 0x00, lcm.Fon}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("received functions = %v, want %v", got, want)
+	}
+
+	m.Close()
+	if err := m.FlushMCU(); !errors.Is(err, lcm.ErrClosed) {
+		t.Errorf("FlushMCU() after Close() = %v, want %v", err, lcm.ErrClosed)
+	}
+}
@@ -9,4 +9,5 @@ require (
 	github.com/shirou/gopsutil/v3 v3.22.1
 	github.com/warthog618/gpiod v0.8.0
 	golang.org/x/sys v0.3.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1
 )
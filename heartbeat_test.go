@@ -0,0 +1,36 @@
+package lcm_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mafredri/lcm"
+)
+
+func TestLCM_Heartbeat(t *testing.T) {
+	m, f := openFake(t, nil, lcm.WithHeartbeat(5*time.Millisecond))
+
+	for _, msg := range waitReceived(t, f, 2) {
+		if !bytes.Equal(msg, lcm.DisplayStatus) {
+			t.Errorf("received %v, want heartbeat", msg)
+		}
+	}
+
+	if err := m.Send(lcm.DisplayOff); err != nil {
+		t.Fatal(err)
+	}
+	// Only the absence of heartbeats can be checked, wait for a
+	// number of intervals.
+	time.Sleep(50 * time.Millisecond)
+
+	msgs := f.Received()
+	off := len(msgs) - 1
+	for !bytes.Equal(msgs[off], lcm.DisplayOff) {
+		off--
+	}
+	// A heartbeat that raced with DisplayOff may be written after it.
+	if n := len(msgs) - off - 1; n > 1 {
+		t.Errorf("heartbeats while display off = %d, want at most 1", n)
+	}
+}
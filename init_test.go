@@ -0,0 +1,30 @@
+package lcm_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mafredri/lcm"
+)
+
+func TestLCM_Init(t *testing.T) {
+	m, f := openFake(t, nil)
+
+	if err := m.Init(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []lcm.Message{lcm.DisplayOn, lcm.DisplayStatus, lcm.ClearDisplay}
+	got := f.Received()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Received() = %v, want %v", got, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := m.Init(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Init() canceled error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,67 @@
+package lcm
+
+import (
+	"strings"
+)
+
+// Layout word-wraps the text across the two display lines, breaking on
+// spaces. Words that are longer than a line are cut. When the text does
+// not fit on the display, the bottom line is truncated with an ellipsis
+// (see Truncate), consider using Scroll for such text instead.
+//
+// The returned lines are padded with spaces to the full width of the
+// display and ready for SetDisplay. The text is measured in bytes, like
+// SetDisplay, so it should be transliterated first (see Transliterate).
+func Layout(text string) (top, bottom string) {
+	lines := wrap(text, 16)
+	switch len(lines) {
+	case 0:
+	case 1:
+		top = lines[0]
+	default:
+		top = lines[0]
+		bottom = strings.Join(lines[1:], " ")
+		if len(lines) > 2 {
+			bottom = Truncate(bottom, 16)
+		}
+	}
+	return padRight(top, 16), padRight(bottom, 16)
+}
+
+// wrap the text into lines of at most width bytes.
+func wrap(text string, width int) []string {
+	var lines []string
+	var cur string
+	for _, w := range strings.Fields(text) {
+		for w != "" {
+			sep := ""
+			if cur != "" {
+				sep = " "
+			}
+			switch room := width - len(cur) - len(sep); {
+			case len(w) <= room:
+				cur += sep + w
+				w = ""
+			case len(w) > width && room > 0:
+				// Cut the word at the end of the line.
+				cur += sep + w[:room]
+				w = w[room:]
+				fallthrough
+			default:
+				lines = append(lines, cur)
+				cur = ""
+			}
+		}
+	}
+	if cur != "" {
+		lines = append(lines, cur)
+	}
+	return lines
+}
+
+func padRight(s string, width int) string {
+	if len(s) >= width {
+		return s
+	}
+	return s + strings.Repeat(" ", width-len(s))
+}
@@ -0,0 +1,37 @@
+package lcm
+
+import (
+	"testing"
+)
+
+func TestLayout(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		wantTop    string
+		wantBottom string
+	}{
+		{"Empty", "", "                ", "                "},
+		{"Single word", "Hello", "Hello           ", "                "},
+		{"Fits one line", "Hello world", "Hello world     ", "                "},
+		{"Multi word", "Backup completed successfully", "Backup completed", "successfully    "},
+		{"Wrap on space", "The quick brown fox jumps", "The quick brown ", "fox jumps       "},
+		{"Extra spaces", "  Hello    world  ", "Hello world     ", "                "},
+		{"Long word", "abcdefghijklmnopqrstuvwxyz", "abcdefghijklmnop", "qrstuvwxyz      "},
+		{"Long word after word", "Path /volume1/home/user", "Path /volume1/ho", "me/user         "},
+		{"Exactly 32", "abcdefghijklmnop qrstuvwxyz012345", "abcdefghijklmnop", "qrstuvwxyz012345"},
+		{"Overflow", "The quick brown fox jumps over the lazy dog", "The quick brown ", "fox jumps ove..."},
+		{"Overflow long word", "abcdefghijklmnopqrstuvwxyz0123456789", "abcdefghijklmnop", "qrstuvwxyz012..."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			top, bottom := Layout(tt.text)
+			if top != tt.wantTop || bottom != tt.wantBottom {
+				t.Errorf("Layout() = (%q, %q), want (%q, %q)", top, bottom, tt.wantTop, tt.wantBottom)
+			}
+			if _, err := SetDisplayBoth(top, bottom); err != nil {
+				t.Errorf("SetDisplayBoth() error = %v", err)
+			}
+		})
+	}
+}
@@ -11,9 +11,13 @@ package lcm
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/term"
@@ -46,26 +50,80 @@ const (
 	// forceFlushDelay specifies how long to wait after attempting
 	// to flush the MCU receive buffer.
 	forceFlushDelay = 250 * time.Microsecond
+	// autoPowerCycleInterval limits how often the display is power
+	// cycled, see WithAutoPowerCycle.
+	autoPowerCycleInterval = 5 * time.Minute
 )
 
 // DefaultTTY represents the default serial tty for LCM.
 const DefaultTTY = "/dev/ttyS1"
 
+// ErrClosed is returned when sending to a closed LCM.
+var ErrClosed = errors.New("lcm: closed")
+
+// ErrRetryLimit is returned (as a *SendError) when the display did not
+// acknowledge a message within the retry limit.
+var ErrRetryLimit = errors.New("retry limit exceeded")
+
+// Conn represents the connection to the display, e.g. a serial port.
+type Conn interface {
+	io.ReadWriteCloser
+	// Flush discards data written but not transmitted, and data
+	// received but not read.
+	Flush() error
+}
+
+var _ Conn = (*term.Term)(nil)
+
 // LCM represents the ASUSTOR Liquid Crystal Monitor.
 type LCM struct {
+	dropped   uint64    // Accessed atomically, first for alignment.
+	coalescer coalescer // Contains 64-bit atomics, keep aligned.
+
 	ctx      context.Context
 	cancel   context.CancelFunc
 	done     chan struct{}
-	s        *term.Term
+	s        Conn
 	writeC   chan sendMessage
 	rawReadC chan Message
 	readC    chan []byte
+	buttonC  chan Button
+	buttons  int32 // Set (atomically) when Buttons has been called.
 	opts     openOptions
+
+	lastPowerCycle time.Time // Only accessed by handle.
+	cycling        int32     // Set (atomically) during power cycle.
+	displayOff     int32     // Set (atomically) when DisplayOff is sent.
+	writing        int32     // Set (atomically) while a write is in flight.
+
+	versionMu sync.Mutex
+	version   []byte        // Cached MCU version.
+	versionC  chan struct{} // Closed when the version is received.
+
+	// Tickets guarantee that sends are queued in call order, see
+	// enqueue.
+	queueMu   sync.Mutex
+	queueCond *sync.Cond
+	ticket    uint64 // Next ticket to hand out.
+	serving   uint64 // Ticket allowed to queue.
 }
 
 type openOptions struct {
-	ack bool
-	l   Logger
+	ack         bool
+	autoDetect  bool
+	power       *Power
+	overflow    OverflowPolicy
+	debounce    time.Duration
+	coalesce    bool
+	minInterval time.Duration
+	heartbeat   time.Duration
+	l           Logger
+	verbose     bool
+	tap         TapFunc
+	replyMatch  ReplyMatcher
+	// logAttrs, when set, is used for structured logging, see
+	// WithSlog and logEvent.
+	logAttrs func(level logLevel, msg string, kv ...interface{})
 }
 
 // OpenOption configures LCM during open.
@@ -86,6 +144,146 @@ func EnableProtocolAckReply() OpenOption {
 	}
 }
 
+// WithAutoDetect enables detection of the LCM serial port when the tty
+// passed to Open is empty, see DetectTTY.
+func WithAutoDetect() OpenOption {
+	return func(o *openOptions) {
+		o.autoDetect = true
+	}
+}
+
+// WithHeartbeat periodically sends DisplayStatus to the display while it
+// is on, as a keep-alive. Heartbeats are queued like any other send so
+// they never interleave with (or starve) other writes, and they are
+// paused while the display is off (after sending DisplayOff).
+//
+// Whether this improves stability is unverified, lcmd issues
+// DisplayStatus around text updates but the firmware has not been
+// observed to require a keep-alive. Disabled by default.
+func WithHeartbeat(interval time.Duration) OpenOption {
+	return func(o *openOptions) {
+		o.heartbeat = interval
+	}
+}
+
+// WithAutoPowerCycle power cycles the display using p when a command
+// exceeds the retry limit, i.e. the display is unresponsive. After the
+// power cycle the display is turned on, but the text must be restored
+// by the caller. Power cycles are limited to one every five minutes so
+// that a dead display is not cycled in a loop.
+func WithAutoPowerCycle(p *Power) OpenOption {
+	return func(o *openOptions) {
+		o.power = p
+	}
+}
+
+// OverflowPolicy decides what happens to messages (and button presses)
+// from the display when the receive buffer is full.
+type OverflowPolicy int
+
+// OverflowPolicy enums.
+const (
+	// DropOldest discards the earliest message in the buffer.
+	DropOldest OverflowPolicy = iota
+	// DropNewest discards the new message.
+	DropNewest
+	// Block waits until there is room in the buffer. Note that this
+	// stalls all communication with the display (including sends)
+	// if Recv (or Buttons) is not drained.
+	Block
+)
+
+// WithReadOverflow sets the policy for when the receive buffer is full
+// (default DropOldest). Dropped messages are counted in Stats.
+func WithReadOverflow(policy OverflowPolicy) OpenOption {
+	return func(o *openOptions) {
+		o.overflow = policy
+	}
+}
+
+// DefaultButtonDebounce is the default window for WithButtonDebounce.
+const DefaultButtonDebounce = 50 * time.Millisecond
+
+// WithButtonDebounce discards a button press when it repeats the
+// previous press within window (default DefaultButtonDebounce), some
+// units send the same button command twice for a single press. Presses
+// of different buttons, or after the window, are not affected. A window
+// of zero disables debouncing.
+//
+// Button presses are debounced both for Buttons and Recv.
+func WithButtonDebounce(window time.Duration) OpenOption {
+	return func(o *openOptions) {
+		o.debounce = window
+	}
+}
+
+// Direction represents the direction of traffic, see WithTap.
+type Direction int
+
+// Direction enums.
+const (
+	In  Direction = iota // From the display.
+	Out                  // To the display.
+)
+
+func (d Direction) String() string {
+	if d == In {
+		return "in"
+	}
+	return "out"
+}
+
+// TapFunc is called for every frame read from or written to the
+// display, see WithTap.
+type TapFunc func(dir Direction, frame Message, t time.Time)
+
+// WithTap calls tap for every frame read from (In) or written to (Out)
+// the display, e.g. for recording or analyzing traffic. The frames are
+// as seen on the wire, including the checksum. Only valid frames are
+// passed for In, while Out includes retries, flushes and raw writes
+// (see Write).
+//
+// The tap is called synchronously from the read and write loops, it
+// must not block or modify the frame.
+func WithTap(tap TapFunc) OpenOption {
+	return func(o *openOptions) {
+		o.tap = tap
+	}
+}
+
+// ReplyMatcher reports if recv is the reply to the sent command (done)
+// and if the command was successful (ok). Frames for which done is false
+// are handled as usual, e.g. as button presses. Both messages are passed
+// without checksum.
+type ReplyMatcher func(sent, recv Message) (done, ok bool)
+
+// DefaultReplyMatcher matches a Reply with the same function as the
+// sent command, a non-zero payload means the command failed.
+func DefaultReplyMatcher(sent, recv Message) (done, ok bool) {
+	if recv.Type() != Reply || recv.Function() != sent.Function() {
+		return false, false
+	}
+	return true, recv.Ok()
+}
+
+// WithReplyMatcher sets how replies are matched to the sent commands
+// (default DefaultReplyMatcher), for displays with firmware that replies
+// differently. Commands that are not matched (or not ok) are retried.
+//
+// To build a matcher for a new model, capture the traffic between lcmd
+// and the display with lcm-monitor (see cmd/lcm-monitor) and look at
+// what the display sends after each command:
+//
+//	lcm-monitor -out capture.txt
+//
+// The matcher is called from the goroutine handling the display, it must
+// not block.
+func WithReplyMatcher(match ReplyMatcher) OpenOption {
+	return func(o *openOptions) {
+		o.replyMatch = match
+	}
+}
+
 // Logger represents a generic logger (e.g. from the log package).
 type Logger interface {
 	Printf(format string, v ...interface{})
@@ -95,13 +293,60 @@ type noopLogger struct{}
 
 func (noopLogger) Printf(format string, v ...interface{}) {}
 
-// WithLogger sets the logger used by LCM (default none).
+// WithLogger sets the logger used by LCM (default none). Only retries,
+// timeouts and errors are logged unless verbose logging is enabled, see
+// WithVerbose.
 func WithLogger(l Logger) OpenOption {
 	return func(o *openOptions) {
 		o.l = l
 	}
 }
 
+// WithVerbose enables (or disables) verbose logging of every read and
+// write, useful for debugging the protocol. It has no effect with
+// WithSlog, where the level of the handler decides.
+func WithVerbose(verbose bool) OpenOption {
+	return func(o *openOptions) {
+		o.verbose = verbose
+	}
+}
+
+// logLevel is the severity of a log message.
+type logLevel int
+
+const (
+	levelDebug logLevel = iota // Every read and write, see WithVerbose.
+	levelInfo                  // Retries, timeouts and unexpected messages.
+	levelWarn                  // Write errors and power cycles.
+	levelError                 // Failed sends and fatal errors.
+)
+
+// logf logs the formatted message at the given level.
+func (m *LCM) logf(level logLevel, format string, v ...interface{}) {
+	m.logEvent(level, nil, format, v...)
+}
+
+// logs reports if messages at the given level are logged, callers on
+// the hot path (every read and write) check it before building the
+// key-value pairs for logEvent.
+func (m *LCM) logs(level logLevel) bool {
+	return m.opts.logAttrs != nil || level != levelDebug || m.opts.verbose
+}
+
+// logEvent logs the formatted message at the given level. With
+// structured logging (see WithSlog) the key-value pairs are included as
+// attributes, otherwise they are omitted.
+func (m *LCM) logEvent(level logLevel, kv []interface{}, format string, v ...interface{}) {
+	if m.opts.logAttrs != nil {
+		m.opts.logAttrs(level, fmt.Sprintf(format, v...), kv...)
+		return
+	}
+	if !m.logs(level) {
+		return
+	}
+	m.opts.l.Printf(format, v...)
+}
+
 // Open opens the serial port for LCM.
 func Open(tty string, opt ...OpenOption) (*LCM, error) {
 	opts := openOptions{
@@ -111,6 +356,15 @@ func Open(tty string, opt ...OpenOption) (*LCM, error) {
 		o(&opts)
 	}
 
+	if tty == "" && opts.autoDetect {
+		var err error
+		tty, err = DetectTTY()
+		if err != nil {
+			return nil, err
+		}
+		opts.l.Printf("LCM.Open: detected tty %s", tty)
+	}
+
 	s, err := term.Open(tty, term.Speed(115200), term.RawMode)
 	if err != nil {
 		return nil, err
@@ -122,27 +376,74 @@ func Open(tty string, opt ...OpenOption) (*LCM, error) {
 		return nil, err
 	}
 
+	return OpenConn(s, opt...), nil
+}
+
+// OpenConn uses c for communicating with the display, c should already
+// be flushed. It allows using a transport other than the local serial
+// port, e.g. a serial port over TCP or a fake for testing. The
+// connection is closed by Close.
+func OpenConn(c Conn, opt ...OpenOption) *LCM {
+	opts := openOptions{
+		l:          noopLogger{},
+		replyMatch: DefaultReplyMatcher,
+		debounce:   DefaultButtonDebounce,
+	}
+	for _, o := range opt {
+		o(&opts)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	m := &LCM{
 		ctx:      ctx,
 		cancel:   cancel,
 		done:     make(chan struct{}),
-		s:        s,
+		s:        c,
 		writeC:   make(chan sendMessage, 2),
 		rawReadC: make(chan Message, 2),
 		readC:    make(chan []byte, 5),
+		buttonC:  make(chan Button, 5),
 		opts:     opts,
 	}
+	m.queueCond = sync.NewCond(&m.queueMu)
 
 	go m.read()
 	go m.handle()
+	if opts.heartbeat > 0 {
+		go m.heartbeat(opts.heartbeat)
+	}
 
-	return m, nil
+	return m
+}
+
+// heartbeat sends DisplayStatus every interval while the display is on,
+// see WithHeartbeat.
+func (m *LCM) heartbeat(interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		if atomic.LoadInt32(&m.displayOff) == 1 {
+			continue
+		}
+		err := m.send(DisplayStatus)
+		if err != nil && !errors.Is(err, ErrClosed) {
+			m.logf(levelWarn, "LCM.heartbeat: %v", err)
+		}
+	}
 }
 
 type sendMessage struct {
 	err          chan error
-	data         Message
+	data         []Message
+	raw          bool // Write data as-is without waiting for a reply.
+	flush        bool // Flush the MCU, see FlushMCU.
 	retryLimit   int
 	replyTimeout time.Duration
 	writeDelay   time.Duration
@@ -163,46 +464,491 @@ type sendMessage struct {
 // Other attemps included sending enough zero bytes to clear the receive
 // buffer, but while effective, not foolproof (a good number of bytes
 // was 32 or 33) but still unrecoverable states were observed.
+//
+// Must only be called from handle, see write.
 func (m *LCM) forceFlushMCU() {
-	m.opts.l.Printf("LCM.forceFlushMCU: trying to flush MCU read buffer...")
+	m.logf(levelDebug, "LCM.forceFlushMCU: trying to flush MCU read buffer...")
 
 	data := make([]byte, len(flushMCUBuffer), len(flushMCUBuffer)+1*2)
 	copy(data, flushMCUBuffer)
-	sum := checksum(data)
+	sum := Checksum(data)
 	data = append(data, sum)
 	data = append(data, data...)
 
-	_, _ = m.s.Write(data)
+	_ = m.write(data)
 
 	// Small delay to allow the MCU to process the message.
 	time.Sleep(forceFlushDelay)
 }
 
+// FlushMCU tries to flush the MCU receive buffer, e.g. to recover when
+// the display replies with an error to every command or garbage is
+// received via Recv. The flush is queued like any other write.
+//
+// This is a best-effort escape hatch, the same flush is issued
+// automatically when the display does not reply in time. It has been
+// observed to get the MCU out of a stuck state, but not always, in
+// which case power cycling is the only known remedy (see Power).
+func (m *LCM) FlushMCU() error {
+	sm := sendMessage{
+		err:   make(chan error, 1),
+		flush: true,
+	}
+	return m.enqueue(sm)
+}
+
+// autoPowerCycle power cycles the display in the background, if enabled
+// and not rate limited, see WithAutoPowerCycle.
+func (m *LCM) autoPowerCycle() {
+	p := m.opts.power
+	if p == nil {
+		return
+	}
+	if !m.lastPowerCycle.IsZero() && time.Since(m.lastPowerCycle) < autoPowerCycleInterval {
+		m.logf(levelWarn, "LCM.autoPowerCycle: rate limited, last power cycle at %s", m.lastPowerCycle.Format(time.RFC3339))
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&m.cycling, 0, 1) {
+		return
+	}
+	m.lastPowerCycle = time.Now()
+
+	go func() {
+		defer atomic.StoreInt32(&m.cycling, 0)
+
+		m.logf(levelWarn, "LCM.autoPowerCycle: display unresponsive, power cycling...")
+		err := p.CycleContext(m.ctx)
+		if err != nil {
+			m.logf(levelError, "LCM.autoPowerCycle: power cycle failed: %v", err)
+			return
+		}
+		for _, msg := range []Message{DisplayOn, DisplayStatus} {
+			err = m.Send(msg)
+			if err != nil {
+				m.logf(levelError, "LCM.autoPowerCycle: init failed: %v", err)
+				return
+			}
+		}
+		m.logf(levelInfo, "LCM.autoPowerCycle: done")
+	}()
+}
+
 // Send messages to the display. Note that checksum should be omitted,
 // it is handled transparently as part of the protocol implementation.
 //
-// TODO(mafredri): Add support for functional arguments:
+// Send is safe for concurrent use, messages are written in the order
+// Send was called (FIFO).
+//
+// Send does not take SendOptions, a variadic signature would no longer
+// satisfy interfaces such as Send(Message) error. Use TrySend to send
+// with options, e.g.:
 //
-//	m.Send(msg, lcm.WithRetryLimit(100), lcm.WithReplyTimeout(5 * time.Millisecond))
+//	m.TrySend(msg, lcm.WithRetryLimit(100), lcm.WithReplyTimeout(5*time.Millisecond))
 func (m *LCM) Send(msg Message) error {
-	err := msg.Check()
+	return m.send(msg)
+}
+
+// SendBatch sends the messages to the display as one unit, no other
+// writes are interleaved. Sending stops at the first message that fails,
+// in which case a *BatchError is returned. An empty batch is a no-op.
+func (m *LCM) SendBatch(msgs ...Message) error {
+	return m.send(msgs...)
+}
+
+// BatchError reports which message of a batch failed, see SendBatch.
+type BatchError struct {
+	Index int     // Index of the message that failed.
+	Msg   Message // Message that failed.
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("batch message %d (%v): %v", e.Index, e.Msg.Function(), e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// SendError is returned by Send when the display did not acknowledge a
+// message within the retry limit. Batches report it wrapped in a
+// *BatchError.
+type SendError struct {
+	Retries       int       // Number of tries after the first one.
+	RetryLimit    int       // Retry limit of the send.
+	LastErrorCode ErrorCode // Last error reply, zero if there was none.
+	TimedOut      bool      // Set if the last try was not replied to.
+	Err           error     // Last write error, if any.
+}
+
+func (e *SendError) Error() string {
+	s := fmt.Sprintf("%v: %d/%d", ErrRetryLimit, e.Retries, e.RetryLimit)
+	switch {
+	case e.TimedOut && e.LastErrorCode != 0:
+		s += fmt.Sprintf(": no reply (last display error %v)", e.LastErrorCode)
+	case e.TimedOut:
+		s += ": no reply"
+	default:
+		s += fmt.Sprintf(": display error %v", e.LastErrorCode)
+	}
+	if e.Err != nil {
+		s += fmt.Sprintf(": last write error: %v", e.Err)
+	}
+	return s
+}
+
+func (e *SendError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports if target is ErrRetryLimit.
+func (e *SendError) Is(target error) bool {
+	return target == ErrRetryLimit
+}
+
+// SetLines sets the text on both lines of the display, the messages
+// are written back-to-back without other writes in between. See
+// SetDisplayBoth.
+func (m *LCM) SetLines(top, bottom string) error {
+	msgs, err := SetDisplayBoth(top, bottom)
 	if err != nil {
 		return err
 	}
+	return m.send(msgs...)
+}
+
+// initSequence is the startup sequence of the display, as observed in
+// lcmd.
+var initSequence = []struct {
+	msg  Message
+	desc string
+}{
+	// The display may have been turned off by a previous program.
+	{DisplayOn, "display on"},
+	// Always issued after DisplayOn in the lcmd init-routine, its
+	// purpose is unknown.
+	{DisplayStatus, "display status"},
+	// Removes any stale text, e.g. "Starting system please wait"
+	// shown by the MCU on boot.
+	{ClearDisplay, "clear display"},
+}
+
+// Init runs the startup sequence of the display (display on, status
+// and clear), as observed in lcmd. The display is blank afterwards.
+func (m *LCM) Init(ctx context.Context) error {
+	for _, step := range initSequence {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := m.send(step.msg); err != nil {
+			return fmt.Errorf("init: %s: %w", step.desc, err)
+		}
+	}
+	return nil
+}
+
+// SendOption configures a send.
+type SendOption func(*sendOptions)
+
+type sendOptions struct {
+	retryLimit   int
+	replyTimeout time.Duration
+	try          bool // Don't block when the queue is full, see TrySend.
+}
+
+// WithRetryLimit sets how many times the message is retried (default
+// DefaultRetryLimit).
+func WithRetryLimit(n int) SendOption {
+	return func(o *sendOptions) {
+		o.retryLimit = n
+	}
+}
+
+// WithReplyTimeout sets how long to wait for a reply before retrying
+// (default DefaultReplyTimeout).
+func WithReplyTimeout(d time.Duration) SendOption {
+	return func(o *sendOptions) {
+		o.replyTimeout = d
+	}
+}
+
+// errQueueFull is returned by tryEnqueue when the message can't be
+// queued without blocking.
+var errQueueFull = errors.New("lcm: queue full")
+
+// TrySend is like Send except that it does not wait for the queue when
+// other writes are pending (e.g. the display is slow to reply and
+// writes are being retried), false is returned instead and the message
+// is not sent. When the message was queued, TrySend waits for the write
+// to complete and returns true along with the result.
+func (m *LCM) TrySend(msg Message, opts ...SendOption) (bool, error) {
+	// Copy, appending could modify the caller's backing array.
+	opts = append(append([]SendOption(nil), opts...), func(o *sendOptions) { o.try = true })
+	err := m.sendOpts(opts, msg)
+	if errors.Is(err, errQueueFull) {
+		return false, nil
+	}
+	return true, err
+}
 
-	data := make([]byte, len(msg), len(msg)+1)
-	copy(data, msg)
-	data = append(data, checksum(data))
+// send the messages to the display as one unit, each message must
+// complete (or fail) before the next one is written. When sending more
+// than one message, errors are reported as *BatchError.
+func (m *LCM) send(msgs ...Message) error {
+	return m.sendOpts(nil, msgs...)
+}
+
+// sendOpts is like send, with options.
+func (m *LCM) sendOpts(opt []SendOption, msgs ...Message) error {
+	opts := sendOptions{
+		retryLimit:   DefaultRetryLimit,
+		replyTimeout: DefaultReplyTimeout,
+	}
+	for _, o := range opt {
+		o(&opts)
+	}
+
+	for i, msg := range msgs {
+		err := msg.Check()
+		if err != nil {
+			if len(msgs) > 1 {
+				return &BatchError{Index: i, Msg: msg, Err: err}
+			}
+			return err
+		}
+	}
+
+	if !m.opts.coalesce {
+		return m.sendChecked(opts, msgs)
+	}
+
+	send, index := m.coalescer.filter(msgs)
+	if len(send) == 0 {
+		m.logf(levelDebug, "LCM.send: coalesced %d message(s)", len(msgs))
+		return nil
+	}
+	m.coalescer.wait(m.opts.minInterval)
+
+	err := m.sendChecked(opts, send)
+	failed := len(send)
+	var batchErr *BatchError
+	switch {
+	case errors.As(err, &batchErr):
+		failed = batchErr.Index
+		batchErr.Index = index[batchErr.Index]
+	case err != nil:
+		failed = 0
+		if len(msgs) > 1 {
+			// Report the error as part of the batch that
+			// was given to us.
+			err = &BatchError{Index: index[0], Msg: send[0], Err: err}
+		}
+	}
+	m.coalescer.update(send, failed)
+	return err
+}
+
+// sendChecked sends the (valid) messages, see send.
+func (m *LCM) sendChecked(opts sendOptions, msgs []Message) error {
+	if len(msgs) == 0 {
+		return nil // Nothing to write.
+	}
+	data := make([]Message, 0, len(msgs))
+	for _, msg := range msgs {
+		switch {
+		case bytes.Equal(msg, DisplayOff):
+			atomic.StoreInt32(&m.displayOff, 1)
+		case bytes.Equal(msg, DisplayOn):
+			atomic.StoreInt32(&m.displayOff, 0)
+		}
+
+		d := make([]byte, len(msg), len(msg)+1)
+		copy(d, msg)
+		d = append(d, Checksum(d))
+		data = append(data, d)
+	}
 
 	sm := sendMessage{
 		err:          make(chan error, 1),
 		data:         data,
-		retryLimit:   DefaultRetryLimit,
-		replyTimeout: DefaultReplyTimeout,
+		retryLimit:   opts.retryLimit,
+		replyTimeout: opts.replyTimeout,
 		writeDelay:   DefaultWriteDelay,
 	}
-	m.writeC <- sm
-	return <-sm.err
+	if opts.try {
+		return m.tryEnqueue(sm)
+	}
+	return m.enqueue(sm)
+}
+
+// enqueue the message for writing and wait for the result.
+//
+// Blocked channel senders are not guaranteed to be served in order, so
+// a ticket is taken on entry and only the holder of the current ticket
+// may queue on writeC. This guarantees FIFO ordering between callers.
+func (m *LCM) enqueue(sm sendMessage) error {
+	m.queueMu.Lock()
+	t := m.ticket
+	m.ticket++
+	for m.serving != t {
+		m.queueCond.Wait()
+	}
+	m.queueMu.Unlock()
+
+	var err error
+	select {
+	case m.writeC <- sm:
+	case <-m.done:
+		err = ErrClosed
+	}
+
+	m.queueMu.Lock()
+	m.serving++
+	m.queueCond.Broadcast()
+	m.queueMu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	return m.wait(sm)
+}
+
+// tryEnqueue is like enqueue, except errQueueFull is returned if the
+// message can't be queued immediately.
+func (m *LCM) tryEnqueue(sm sendMessage) error {
+	m.queueMu.Lock()
+	// Holding queueMu keeps new tickets from being handed out, so
+	// the FIFO order is kept.
+	if m.serving != m.ticket {
+		m.queueMu.Unlock()
+		return errQueueFull // Others are waiting to queue.
+	}
+	select {
+	case <-m.done:
+		m.queueMu.Unlock()
+		return ErrClosed
+	case m.writeC <- sm:
+		m.queueMu.Unlock()
+	default:
+		m.queueMu.Unlock()
+		return errQueueFull
+	}
+	return m.wait(sm)
+}
+
+// wait for the queued message to be written.
+func (m *LCM) wait(sm sendMessage) error {
+	select {
+	case err := <-sm.err:
+		return err
+	case <-m.done:
+		// The message may have completed right before close.
+		select {
+		case err := <-sm.err:
+			return err
+		default:
+			return ErrClosed
+		}
+	}
+}
+
+// Version returns the MCU version, it is requested from the display on
+// first call and cached thereafter. Requesting the version takes 200+ms.
+func (m *LCM) Version(ctx context.Context) (major, minor, patch int, err error) {
+	for {
+		m.versionMu.Lock()
+		if v := m.version; v != nil {
+			m.versionMu.Unlock()
+			return int(v[0]), int(v[1]), int(v[2]), nil
+		}
+		// Only request the version if there is no request in-flight.
+		request := m.versionC == nil
+		if request {
+			m.versionC = make(chan struct{})
+		}
+		c := m.versionC
+		m.versionMu.Unlock()
+
+		if request {
+			err = m.Send(RequestVersion)
+			if err != nil {
+				m.abortVersion(c)
+				return 0, 0, 0, err
+			}
+		}
+
+		select {
+		case <-c:
+		case <-ctx.Done():
+			if request {
+				m.abortVersion(c)
+			}
+			return 0, 0, 0, ctx.Err()
+		}
+
+		m.versionMu.Lock()
+		v := m.version
+		m.versionMu.Unlock()
+		if v != nil {
+			return int(v[0]), int(v[1]), int(v[2]), nil
+		}
+		// The request was aborted by its caller, try again.
+	}
+}
+
+// abortVersion wakes the callers waiting for the version request c so
+// that the next one can request again.
+func (m *LCM) abortVersion(c chan struct{}) {
+	m.versionMu.Lock()
+	defer m.versionMu.Unlock()
+
+	if m.versionC == c {
+		close(c)
+		m.versionC = nil
+	}
+}
+
+// setVersion caches the version and notifies waiters.
+func (m *LCM) setVersion(v []byte) {
+	if len(v) != 3 {
+		return
+	}
+	m.versionMu.Lock()
+	defer m.versionMu.Unlock()
+
+	m.version = append([]byte(nil), v...)
+	if m.versionC != nil {
+		close(m.versionC)
+		m.versionC = nil
+	}
+}
+
+// Stats represents LCM statistics.
+type Stats struct {
+	// Dropped is the number of messages (including button presses)
+	// dropped due to a full receive buffer.
+	Dropped uint64
+	// Coalesced is the number of messages skipped because they would
+	// not change the display, see WithCoalescing.
+	Coalesced uint64
+	// Pending is the number of queued writes, see Pending.
+	Pending int
+}
+
+// Stats returns the current statistics.
+func (m *LCM) Stats() Stats {
+	return Stats{
+		Dropped:   atomic.LoadUint64(&m.dropped),
+		Coalesced: atomic.LoadUint64(&m.coalescer.skipped),
+		Pending:   m.Pending(),
+	}
+}
+
+// Pending returns the number of writes waiting to be handled, including
+// the one in flight (e.g. waiting for a reply). A climbing number means
+// the display can't keep up and the caller should back off.
+func (m *LCM) Pending() int {
+	return len(m.writeC) + int(atomic.LoadInt32(&m.writing))
 }
 
 // Recv messages sent from the display.
@@ -210,43 +956,121 @@ func (m *LCM) Recv() Message {
 	return <-m.readC
 }
 
+// RecvContext is like Recv, except it returns ctx.Err() if the context
+// is canceled before a message is received and ErrClosed once the LCM
+// is closed, allowing receive loops to exit cleanly.
+func (m *LCM) RecvContext(ctx context.Context) (Message, error) {
+	select {
+	case b := <-m.readC:
+		return b, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-m.done:
+		return nil, ErrClosed
+	}
+}
+
+// Read is an alias for Recv.
+func (m *LCM) Read() Message {
+	return m.Recv()
+}
+
+// Write the raw bytes to the display as-is, e.g. when proxying frames
+// from another source. The data should include the checksum.
+//
+// This is an advanced use-case, the data is not validated and no reply
+// is waited for (nor retried). Writes are still serialized with Send,
+// i.e. the data is never written while waiting for a reply.
+func (m *LCM) Write(b []byte) error {
+	if m.opts.coalesce {
+		// The effect of raw data is unknown.
+		m.coalescer.forget()
+	}
+
+	data := make(Message, len(b))
+	copy(data, b)
+
+	sm := sendMessage{
+		err:        make(chan error, 1),
+		data:       []Message{data},
+		raw:        true,
+		writeDelay: DefaultWriteDelay,
+	}
+	return m.enqueue(sm)
+}
+
+// Buttons returns a channel for button presses. Once called, button
+// press commands are no longer received via Recv. The channel is
+// buffered and the earliest button press is discarded when full.
+func (m *LCM) Buttons() <-chan Button {
+	atomic.StoreInt32(&m.buttons, 1)
+	return m.buttonC
+}
+
 // read the serial port and transmit
 // messages on the read channel.
 func (m *LCM) read() {
 	var parseErr parsingError
 	// No need for a large buffer, the most common message length is 5.
-	r := bufio.NewReaderSize(m.s, 16)
+	r := &resyncReader{r: bufio.NewReaderSize(m.s, 16)}
 	raw := &recvMessage{}
 	for {
 		raw.Reset()
 		err := copyBytes(raw, r)
 		if err != nil {
 			if errors.As(err, &parseErr) {
-				m.opts.l.Printf("LCM.read: %v", err)
+				m.logf(levelInfo, "LCM.read: %v", err)
+				r.resync(raw.Bytes())
 				continue
 			}
 			// TODO(mafredri): Close LCM.
-			m.opts.l.Printf("LCM.read: fatal: %v", err)
+			m.logf(levelError, "LCM.read: fatal: %v", err)
 			return
 		}
 
 		b := Message(raw.Bytes())
-		m.opts.l.Printf("LCM.read: OK %#x", b)
+		if m.opts.tap != nil {
+			m.opts.tap(In, b, time.Now())
+		}
+		if m.logs(levelDebug) {
+			m.logEvent(levelDebug, []interface{}{"dir", In, "frame", fmt.Sprintf("%#x", []byte(b)), "type", b.Type(), "fn", b.Function()},
+				"LCM.read: OK %#x", b)
+		}
 		m.rawReadC <- b
 	}
 }
 
 // write to the serial port.
+//
+// All writes to the connection go through write and must only be made
+// from the handle goroutine (single-writer), this guarantees that the
+// bytes of different frames (e.g. a flush and a retry) are never
+// interleaved on the wire. Other goroutines queue writes via writeC.
 func (m *LCM) write(data []byte) error {
-	n, err := m.s.Write(data)
-	m.opts.l.Printf("LCM.write: wrote: %#x %d, err: %v", data, n, err)
-	if err != nil {
-		return err
+	if m.opts.tap != nil {
+		m.opts.tap(Out, data, time.Now())
+	}
+	// A short write would truncate the frame, write the remainder
+	// until the frame is complete or the write fails.
+	for len(data) > 0 {
+		n, err := m.s.Write(data)
+		if m.logs(levelDebug) {
+			m.logEvent(levelDebug, []interface{}{"dir", Out, "frame", fmt.Sprintf("%#x", data), "n", n, "err", err},
+				"LCM.write: wrote: %#x %d, err: %v", data, n, err)
+		}
+		if err != nil {
+			return err
+		}
+		if n <= 0 || n > len(data) {
+			return io.ErrShortWrite
+		}
+		data = data[n:]
 	}
 	return nil
 }
 
-// handle incoming and outgoing messages.
+// handle incoming and outgoing messages. It is the only goroutine that
+// writes to the display, see write.
 func (m *LCM) handle() {
 	defer close(m.done)
 
@@ -254,6 +1078,25 @@ func (m *LCM) handle() {
 	var retry func()
 	var handleReply func(Message) bool
 	var replyTimeout <-chan time.Time
+	var pending chan error // Error channel of the in-flight write.
+	var lastButton Button  // Last forwarded button, see WithButtonDebounce.
+	var lastButtonAt time.Time
+
+	// closed fails the in-flight and queued writes.
+	closed := func() {
+		atomic.StoreInt32(&m.writing, 0)
+		if pending != nil {
+			pending <- ErrClosed
+		}
+		for {
+			select {
+			case w := <-m.writeC:
+				w.err <- ErrClosed
+			default:
+				return
+			}
+		}
+	}
 
 	for {
 		var read Message
@@ -266,11 +1109,12 @@ func (m *LCM) handle() {
 			case read = <-m.rawReadC:
 
 			case <-replyTimeout:
-				m.opts.l.Printf("LCM.handle: write(%d): timeout, retry...", id)
+				m.logEvent(levelInfo, []interface{}{"id", id}, "LCM.handle: write(%d): timeout, retry...", id)
 				m.forceFlushMCU()
 				retry()
 
 			case <-m.ctx.Done():
+				closed()
 				return
 			}
 		} else {
@@ -281,44 +1125,105 @@ func (m *LCM) handle() {
 			// before the next one is handled.
 			case w := <-m.writeC:
 				id++
-				m.opts.l.Printf("LCM.handle: write(%d): %#x", id, w.data)
+				atomic.StoreInt32(&m.writing, 1)
+				if m.logs(levelDebug) {
+					m.logEvent(levelDebug, []interface{}{"id", id, "frame", fmt.Sprintf("%#x", w.data)}, "LCM.handle: write(%d): %#x", id, w.data)
+				}
+
+				if w.raw {
+					time.Sleep(w.writeDelay)
+					err := m.write(w.data[0])
+					atomic.StoreInt32(&m.writing, 0)
+					w.err <- err
+					continue
+				}
+				if w.flush {
+					m.forceFlushMCU()
+					atomic.StoreInt32(&m.writing, 0)
+					close(w.err)
+					continue
+				}
+
+				cur := 0 // Index of the message being written.
+				tries := 0
+				var wErr error
+				var lastCode ErrorCode // Last error reply to the current message.
+				replied := false       // Set when the current try is replied to.
+				pending = w.err
 
 				// Define reply function for verifying
 				// that the command was successful.
 				handleReply = func(reply Message) bool {
-					if reply.Type() == Reply && reply.Function() == w.data.Function() {
-						if reply.Ok() {
-							m.opts.l.Printf("LCM.handle: write(%d): reply OK", id)
+					sent := w.data[cur]
+					done, ok := m.opts.replyMatch(sent[:len(sent)-1], reply[:len(reply)-1])
+					consume := true
+					if !done && sent.Function() == Fversion && reply.Type() == Command && reply.Function() == Fversion {
+						// The version implies that the request
+						// was received, even if the ack was lost
+						// (or is late), so there's no need to
+						// request it again, see RequestVersion.
+						// The version is not consumed so that
+						// it's stored and passed on.
+						done, ok, consume = true, true, false
+					}
+					if done {
+						if ok {
+							m.logEvent(levelDebug, []interface{}{"id", id, "fn", reply.Function(), "try", tries}, "LCM.handle: write(%d): reply OK", id)
+							cur++
+							if cur < len(w.data) {
+								// Write the next message in
+								// the batch.
+								tries = 0
+								wErr = nil
+								lastCode = 0
+								retry()
+								return consume
+							}
+							atomic.StoreInt32(&m.writing, 0)
 							close(w.err)
 							handleReply = nil
 							retry = nil
 							replyTimeout = nil
+							pending = nil
 						} else {
+							replied = true
+							lastCode = reply.ErrorCode()
 							// We don't always forceibly flush the MCU here because it had
 							// the sensibility to at least respond to our command.
-							m.opts.l.Printf("LCM.handle: write(%d): reply ERROR (%#x)", id, reply.Value())
+							m.logEvent(levelInfo, []interface{}{"id", id, "fn", reply.Function(), "try", tries, "code", reply.ErrorCode()},
+								"LCM.handle: write(%d): reply ERROR (display error %v)", id, reply.ErrorCode())
 						}
 
-						return true
+						return consume
 					}
 
 					return false
 				}
 
-				tries := 0
-				var wErr error
 				retry = func() {
 					if tries > w.retryLimit {
 						// We gave it a try, not much more we can do...
 						// Caller could try power-cycling the display.
-						if wErr != nil {
-							w.err <- fmt.Errorf("retry limit exceeded: %d/%d: last write error: %w", tries-1, w.retryLimit, wErr)
-						} else {
-							w.err <- fmt.Errorf("retry limit exceeded: %d/%d", tries-1, w.retryLimit)
+						var err error = &SendError{
+							Retries:       tries - 1,
+							RetryLimit:    w.retryLimit,
+							LastErrorCode: lastCode,
+							TimedOut:      !replied,
+							Err:           wErr,
+						}
+						if len(w.data) > 1 {
+							msg := w.data[cur]
+							err = &BatchError{Index: cur, Msg: msg[:len(msg)-1], Err: err}
 						}
+						m.logEvent(levelError, []interface{}{"id", id, "fn", Message(w.data[cur]).Function(), "try", tries - 1, "err", err},
+							"LCM.handle: write(%d): %v", id, err)
+						atomic.StoreInt32(&m.writing, 0)
+						w.err <- err
+						m.autoPowerCycle()
 						handleReply = nil
 						retry = nil
 						replyTimeout = nil
+						pending = nil
 
 						return
 					}
@@ -328,9 +1233,11 @@ func (m *LCM) handle() {
 					time.Sleep(w.writeDelay)
 
 					tries++
-					err := m.write(w.data)
+					replied = false
+					err := m.write(w.data[cur])
 					if err != nil {
-						m.opts.l.Printf("LCM.handle: write(%d): %#x: %v", id, w.data, err)
+						m.logEvent(levelWarn, []interface{}{"id", id, "fn", Message(w.data[cur]).Function(), "try", tries, "err", err},
+							"LCM.handle: write(%d): %#x: %v", id, w.data[cur], err)
 						wErr = err
 					}
 
@@ -340,6 +1247,7 @@ func (m *LCM) handle() {
 				retry() // Initiate first try.
 
 			case <-m.ctx.Done():
+				closed()
 				return
 			}
 		}
@@ -350,11 +1258,18 @@ func (m *LCM) handle() {
 
 		switch read.Type() {
 		case Command:
-			m.opts.l.Printf("LCM.handle: read(Command): %#x", read.Function())
+			m.logf(levelDebug, "LCM.handle: read(Command): %v", read.Function())
 
 			reply := read.ReplyOk()
-			reply = append(reply, checksum(reply))
-			if m.opts.ack {
+			reply = append(reply, Checksum(reply))
+			if read.Function() == Fversion {
+				m.setVersion(read[:len(read)-1].Value())
+
+				// Acknowledging the version often results in
+				// the display thinking we re-requested it, see
+				// RequestVersion.
+				m.logf(levelDebug, "LCM.handle: read(Command): not acknowledging version")
+			} else if m.opts.ack {
 				// A delay is necessary because otherwise the
 				// serial communication protcol is guaranteed
 				// to become corrupt. What usually works quite
@@ -368,49 +1283,127 @@ func (m *LCM) handle() {
 				time.Sleep(DefaultWriteDelay)
 
 				err := m.write(reply)
-				m.opts.l.Printf("LCM.handle: read(Command): sent ack reply %#x, err: %v", reply, err)
+				m.logf(levelDebug, "LCM.handle: read(Command): sent ack reply %#x, err: %v", reply, err)
 			} else {
-				m.opts.l.Printf("LCM.handle: read(Command): protocol ack disabled, not sending reply %#x", reply.Value())
+				m.logf(levelDebug, "LCM.handle: read(Command): protocol ack disabled, not sending reply %#x", reply.Value())
 			}
 
 		case Reply:
 			if read.Function() == fflush {
-				m.opts.l.Printf("LCM.handle: read(Reply): received ack for flush: %#x", read)
+				m.logf(levelDebug, "LCM.handle: read(Reply): received ack for flush: %#x", read)
+			} else if read.Function() == Fversion {
+				// The version was received before the ack.
+				m.logf(levelDebug, "LCM.handle: read(Reply): late ack for version request: %#x", read)
 			} else {
-				m.opts.l.Printf("LCM.handle: read(Reply): unhandled reply (%#x): %#x", read.Function(), read)
+				m.logf(levelInfo, "LCM.handle: read(Reply): unhandled reply (%v): %#x", read.Function(), read)
 			}
 
 		default:
-			m.opts.l.Printf("LCM.handle: read(Unknown): %#x", read)
+			m.logf(levelInfo, "LCM.handle: read(Unknown): %#x", read)
 		}
 
 		read = read[:len(read)-1] // Discard checksum.
-		m.opts.l.Printf("LCM.handle: read: forwarding message: %#x", read)
 
-		select {
-		case m.readC <- read:
+		isButton := read.Type() == Command && read.Function() == Fbutton && len(read.Value()) == 1
+		if isButton {
+			btn := Button(read.Value()[0])
+			now := time.Now()
+			if m.opts.debounce > 0 && btn == lastButton && now.Sub(lastButtonAt) < m.opts.debounce {
+				m.logf(levelDebug, "LCM.handle: read: debounced button: %v", btn)
+				continue
+			}
+			lastButton, lastButtonAt = btn, now
+		}
 
-		default:
+		if isButton && atomic.LoadInt32(&m.buttons) == 1 {
+			btn := Button(read.Value()[0])
+			m.logf(levelDebug, "LCM.handle: read: forwarding button: %v", btn)
+
+			switch m.opts.overflow {
+			case Block:
+				select {
+				case m.buttonC <- btn:
+				case <-m.ctx.Done():
+					closed()
+					return
+				}
+
+			case DropNewest:
+				select {
+				case m.buttonC <- btn:
+				default:
+					atomic.AddUint64(&m.dropped, 1)
+					m.logf(levelInfo, "LCM.handle: read: button buffer full, discarded button")
+				}
+
+			default:
+				select {
+				case m.buttonC <- btn:
+
+				default:
+					select {
+					case <-m.buttonC:
+						atomic.AddUint64(&m.dropped, 1)
+						m.logf(levelInfo, "LCM.handle: read: button buffer full, discarded earliest button")
+					default:
+						// Buffer got depleted.
+					}
+
+					m.buttonC <- btn
+				}
+			}
+			continue
+		}
+
+		m.logf(levelDebug, "LCM.handle: read: forwarding message: %#x", read)
+
+		switch m.opts.overflow {
+		case Block:
+			select {
+			case m.readC <- read:
+			case <-m.ctx.Done():
+				closed()
+				return
+			}
+
+		case DropNewest:
 			select {
-			case <-m.readC:
-				m.opts.l.Printf("LCM.handle: read: buffer full, discarded earliest message")
+			case m.readC <- read:
 			default:
-				// Buffer got depleted.
+				atomic.AddUint64(&m.dropped, 1)
+				m.logf(levelInfo, "LCM.handle: read: buffer full, discarded message")
 			}
 
-			m.readC <- read
+		default:
+			select {
+			case m.readC <- read:
+
+			default:
+				select {
+				case <-m.readC:
+					atomic.AddUint64(&m.dropped, 1)
+					m.logf(levelInfo, "LCM.handle: read: buffer full, discarded earliest message")
+				default:
+					// Buffer got depleted.
+				}
+
+				m.readC <- read
+			}
 		}
 	}
 }
 
-// Close the serial connection.
+// Close the serial connection. Pending and future sends fail with
+// ErrClosed.
 func (m *LCM) Close() error {
 	m.cancel()
 	<-m.done
 	return m.s.Close()
 }
 
-func checksum(b []byte) (s byte) {
+// Checksum calculates the checksum for the message data, it is the sum
+// of all bytes (truncated to one byte).
+func Checksum(b []byte) (s byte) {
 	for _, bb := range b {
 		s += bb
 	}
@@ -1,6 +1,10 @@
 package lcm
 
-import "testing"
+import (
+	"errors"
+	"net"
+	"testing"
+)
 
 func testSetDisplay(t *testing.T, line DisplayLine, indent int, text string) []byte {
 	b, _ := SetDisplay(line, indent, text)
@@ -22,9 +26,62 @@ func Test_sum(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if gotS := checksum(tt.args.b); gotS != tt.wantS {
+			if gotS := Checksum(tt.args.b); gotS != tt.wantS {
 				t.Errorf("sum() = %#x, want %#x", gotS, tt.wantS)
 			}
 		})
 	}
 }
+
+// pipeConn is a Conn without a display on the other end.
+type pipeConn struct {
+	net.Conn
+}
+
+func (pipeConn) Flush() error { return nil }
+
+func TestLCM_TrySend_opts(t *testing.T) {
+	c, other := net.Pipe()
+	defer other.Close()
+	m := OpenConn(pipeConn{c})
+	m.Close()
+
+	// The options have spare capacity, TrySend must not write into it.
+	opts := make([]SendOption, 1, 2)
+	opts[0] = WithRetryLimit(1)
+	spare := opts[:2]
+	spare[1] = func(o *sendOptions) { o.retryLimit = 7 }
+
+	if _, err := m.TrySend(DisplayOn, opts...); !errors.Is(err, ErrClosed) {
+		t.Fatalf("TrySend() error = %v, want %v", err, ErrClosed)
+	}
+
+	var o sendOptions
+	spare[1](&o)
+	if o.retryLimit != 7 || o.try {
+		t.Error("TrySend() modified the backing array of the options")
+	}
+}
+
+// discardConn is a Conn that discards writes.
+type discardConn struct {
+	pipeConn
+}
+
+func (discardConn) Write(b []byte) (int, error) { return len(b), nil }
+
+func TestLCM_write_allocs(t *testing.T) {
+	m := &LCM{s: discardConn{}, opts: openOptions{l: noopLogger{}}}
+	frame := []byte{0xf0, 0x01, 0x11, 0x01, 0x03}
+
+	// Without verbose or structured logging, the debug log of every
+	// write must not allocate.
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := m.write(frame); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("write() allocs = %v, want 0", allocs)
+	}
+}
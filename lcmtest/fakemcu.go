@@ -0,0 +1,231 @@
+// Package lcmtest provides utilities for testing code that uses the
+// lcm package without a real display.
+package lcmtest
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+
+	"github.com/mafredri/lcm"
+)
+
+// ReplyFunc returns the raw bytes (including checksum) written back to
+// the host in response to msg (without checksum). Returning nil means
+// no reply is sent. It must not call methods on the FakeMCU.
+type ReplyFunc func(msg lcm.Message) []byte
+
+// ReplyOk replies OK to commands, the default ReplyFunc.
+func ReplyOk(msg lcm.Message) []byte {
+	return withChecksum(msg.ReplyOk())
+}
+
+// ReplyError replies with an error to commands.
+func ReplyError(msg lcm.Message) []byte {
+	reply := msg.ReplyOk()
+	if reply == nil {
+		return nil
+	}
+	reply[3] = 0x01
+	return withChecksum(reply)
+}
+
+// ReplyVersion returns a ReplyFunc that replies OK to commands and
+// responds to lcm.RequestVersion with the version, like the display:
+//
+//	=> 0xf001130105
+//	<= 0xf101130005 (ack)
+//	<= 0xf0031300010209 (version)
+func ReplyVersion(major, minor, patch byte) ReplyFunc {
+	return func(msg lcm.Message) []byte {
+		b := ReplyOk(msg)
+		if msg.Type() == lcm.Command && msg.Function() == lcm.Fversion {
+			b = append(b, withChecksum(lcm.Message{byte(lcm.Command), 0x03, byte(lcm.Fversion), major, minor, patch})...)
+		}
+		return b
+	}
+}
+
+// NoReply never replies.
+func NoReply(lcm.Message) []byte {
+	return nil
+}
+
+// Corrupt returns a ReplyFunc that corrupts the checksum of the replies
+// from fn.
+func Corrupt(fn ReplyFunc) ReplyFunc {
+	return func(msg lcm.Message) []byte {
+		b := fn(msg)
+		if len(b) > 0 {
+			b[len(b)-1]++
+		}
+		return b
+	}
+}
+
+// FakeMCU is an in-memory fake of the display MCU, it implements
+// lcm.Conn and can be used with lcm.OpenConn. Commands written by the
+// host are parsed and replied to by the ReplyFunc (ReplyOk by default).
+type FakeMCU struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	reply    ReplyFunc
+	in       []byte       // Partial frame written by the host.
+	out      bytes.Buffer // Pending data for the host to read.
+	received []lcm.Message
+	closed   bool
+}
+
+var _ lcm.Conn = (*FakeMCU)(nil)
+
+// NewFakeMCU returns a new FakeMCU.
+func NewFakeMCU() *FakeMCU {
+	f := &FakeMCU{reply: ReplyOk}
+	f.cond = sync.NewCond(&f.mu)
+	return f
+}
+
+// SetReplyFunc sets the function used to reply to frames written by the
+// host.
+func (f *FakeMCU) SetReplyFunc(fn ReplyFunc) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reply = fn
+}
+
+// Received returns the frames written by the host (without checksum),
+// in order.
+func (f *FakeMCU) Received() []lcm.Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]lcm.Message(nil), f.received...)
+}
+
+// WaitReceived waits until the host has written at least n frames and
+// returns them (see Received). Returns an error if ctx is done or the
+// FakeMCU is closed first.
+func (f *FakeMCU) WaitReceived(ctx context.Context, n int) ([]lcm.Message, error) {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			f.mu.Lock()
+			f.cond.Broadcast()
+			f.mu.Unlock()
+		case <-done:
+		}
+	}()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.received) < n {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if f.closed {
+			return nil, io.EOF
+		}
+		f.cond.Wait()
+	}
+	return append([]lcm.Message(nil), f.received...), nil
+}
+
+// Push sends msg (without checksum) to the host as if it came from the
+// display.
+func (f *FakeMCU) Push(msg lcm.Message) {
+	f.PushRaw(withChecksum(msg))
+}
+
+// PushRaw sends b to the host as-is, e.g. to simulate corruption.
+func (f *FakeMCU) PushRaw(b []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.out.Write(b)
+	f.cond.Broadcast()
+}
+
+// PushButton sends a button press to the host.
+func (f *FakeMCU) PushButton(b lcm.Button) {
+	f.Push(lcm.Message{byte(lcm.Command), 0x01, byte(lcm.Fbutton), byte(b)})
+}
+
+// PushVersion sends the MCU version to the host.
+func (f *FakeMCU) PushVersion(major, minor, patch byte) {
+	f.Push(lcm.Message{byte(lcm.Command), 0x03, byte(lcm.Fversion), major, minor, patch})
+}
+
+// Read data sent by the display, blocks until data is available or the
+// FakeMCU is closed.
+func (f *FakeMCU) Read(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for f.out.Len() == 0 && !f.closed {
+		f.cond.Wait()
+	}
+	if f.closed {
+		return 0, io.EOF
+	}
+	return f.out.Read(p)
+}
+
+// Write frames to the display.
+func (f *FakeMCU) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	f.in = append(f.in, p...)
+	for len(f.in) >= 2 {
+		n := int(f.in[1]) + 4 // Header, payload and checksum.
+		if len(f.in) < n {
+			break
+		}
+		frame := f.in[:n:n]
+		f.in = f.in[n:]
+
+		msg := lcm.Message(frame[:n-1])
+		f.received = append(f.received, msg)
+		if !msg.Verify(frame[n-1]) {
+			continue // The MCU ignores frames with a bad checksum.
+		}
+		if b := f.reply(msg); len(b) > 0 {
+			f.out.Write(b)
+		}
+	}
+	f.cond.Broadcast()
+
+	return len(p), nil
+}
+
+// Flush discards pending data in both directions.
+func (f *FakeMCU) Flush() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.in = nil
+	f.out.Reset()
+	return nil
+}
+
+// Close the FakeMCU, pending and future reads return io.EOF.
+func (f *FakeMCU) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	f.cond.Broadcast()
+	return nil
+}
+
+func withChecksum(msg lcm.Message) []byte {
+	if msg == nil {
+		return nil
+	}
+	b := make([]byte, len(msg), len(msg)+1)
+	copy(b, msg)
+	return append(b, lcm.Checksum(b))
+}
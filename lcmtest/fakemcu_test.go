@@ -0,0 +1,145 @@
+package lcmtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mafredri/lcm"
+)
+
+func testOpen(t *testing.T, f *FakeMCU) *lcm.LCM {
+	t.Helper()
+	m := lcm.OpenConn(f)
+	t.Cleanup(func() { m.Close() })
+	return m
+}
+
+func TestFakeMCU_Send(t *testing.T) {
+	// The MCU stops replying after every other frame, emulating
+	// a lost reply.
+	lost := func() ReplyFunc {
+		n := 0
+		return func(msg lcm.Message) []byte {
+			n++
+			if n%2 == 0 {
+				return nil
+			}
+			return ReplyOk(msg)
+		}
+	}
+	// The MCU sends corrupt replies for the first frames.
+	corrupt := func(n int) ReplyFunc {
+		bad := Corrupt(ReplyOk)
+		return func(msg lcm.Message) []byte {
+			if n > 0 {
+				n--
+				return bad(msg)
+			}
+			return ReplyOk(msg)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		reply   ReplyFunc
+		wantErr bool
+	}{
+		{"Reply OK", ReplyOk, false},
+		{"Lost reply", lost(), false},
+		{"Corrupt reply", corrupt(3), false},
+		{"No reply", NoReply, true},
+		{"Reply error", ReplyError, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFakeMCU()
+			f.SetReplyFunc(tt.reply)
+			m := testOpen(t, f)
+
+			err := m.SetLines("Hello", "World")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SetLines() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestFakeMCU_StuckError reproduces the MCU getting stuck replying with
+// an error to every retry of the same command, only another command
+// (see lcm.(*LCM).forceFlushMCU) gets it out of that state.
+func TestFakeMCU_StuckError(t *testing.T) {
+	f := NewFakeMCU()
+	stuck := true
+	f.SetReplyFunc(func(msg lcm.Message) []byte {
+		if msg.Function() == 0x00 { // Flush.
+			stuck = false
+		}
+		if stuck {
+			return ReplyError(msg)
+		}
+		return ReplyOk(msg)
+	})
+	m := testOpen(t, f)
+
+	err := m.Send(lcm.DisplayOn)
+	if err != nil {
+		t.Fatalf("Send() error = %v, want recovery via flush", err)
+	}
+
+	var flushed, resent bool
+	for _, msg := range f.Received() {
+		switch {
+		case msg.Function() == 0x00:
+			flushed = true
+		case flushed && msg.Function() == lcm.Fon:
+			resent = true
+		}
+	}
+	if !flushed || !resent {
+		t.Errorf("flushed = %v, resent = %v; want both", flushed, resent)
+	}
+}
+
+func TestFakeMCU_Push(t *testing.T) {
+	f := NewFakeMCU()
+	m := testOpen(t, f)
+	buttons := m.Buttons()
+
+	f.PushVersion(0, 1, 2)
+	f.PushButton(lcm.Enter)
+
+	select {
+	case b := <-buttons:
+		if b != lcm.Enter {
+			t.Errorf("button = %v, want %v", b, lcm.Enter)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for button")
+	}
+
+	got := m.Recv()
+	if got.Function() != lcm.Fversion || string(got.Value()) != "\x00\x01\x02" {
+		t.Errorf("Recv() = %v, want version 0.1.2", got)
+	}
+}
+
+func TestFakeMCU_WaitReceived(t *testing.T) {
+	f := NewFakeMCU()
+	m := testOpen(t, f)
+
+	go m.SetLines("Hello", "World")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	got, err := f.WaitReceived(ctx, 2)
+	if err != nil || len(got) != 2 {
+		t.Fatalf("WaitReceived() = %d frames, %v, want 2 frames", len(got), err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := f.WaitReceived(ctx, 3); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitReceived() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,59 @@
+package lcm_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mafredri/lcm"
+	"github.com/mafredri/lcm/lcmtest"
+)
+
+type testLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *testLogger) Printf(format string, v ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+func (l *testLogger) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return fmt.Sprint(l.lines)
+}
+
+func TestLCM_WithVerbose(t *testing.T) {
+	tests := []struct {
+		name        string
+		verbose     bool
+		reply       lcmtest.ReplyFunc
+		wantWrites  bool
+		wantTimeout bool
+	}{
+		{"Quiet", false, lcmtest.ReplyOk, false, false},
+		{"Quiet timeout", false, lcmtest.NoReply, false, true},
+		{"Verbose", true, lcmtest.ReplyOk, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &testLogger{}
+			m, _ := openFake(t, tt.reply, lcm.WithLogger(l), lcm.WithVerbose(tt.verbose))
+
+			_, _ = m.TrySend(lcm.DisplayOn, lcm.WithRetryLimit(0), lcm.WithReplyTimeout(time.Millisecond))
+
+			out := l.String()
+			if got := strings.Contains(out, "LCM.write: wrote"); got != tt.wantWrites {
+				t.Errorf("writes logged = %v, want %v\n%s", got, tt.wantWrites, out)
+			}
+			if got := strings.Contains(out, "timeout"); got != tt.wantTimeout {
+				t.Errorf("timeout logged = %v, want %v\n%s", got, tt.wantTimeout, out)
+			}
+		})
+	}
+}
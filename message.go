@@ -3,12 +3,44 @@ package lcm
 import (
 	"errors"
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
+	"unicode/utf8"
+)
+
+// Errors returned when creating or checking messages, they may be
+// wrapped and should be tested for with errors.Is.
+var (
+	ErrMessageTooLong    = errors.New("message data too long")
+	ErrMessageTooShort   = errors.New("message too short")
+	ErrUnknownType       = errors.New("unknown message type")
+	ErrWrongLength       = errors.New("wrong message length")
+	ErrBadDisplayLine    = errors.New("display line out of bounds")
+	ErrIndentOutOfBounds = errors.New("indentation out of bounds, [0, 15]")
+	ErrColumnOutOfBounds = errors.New("column out of bounds, [0, 15]")
+	ErrTextTooLong       = errors.New("text too long")
+	ErrUnknownAlignment  = errors.New("unknown alignment")
 )
 
 // Message represents a serial port message with common bits easily accessible.
 type Message []byte
 
+// NewMessage returns a new message with the length computed from data.
+// The message is validated with Check.
+func NewMessage(t Type, fn Function, data ...byte) (Message, error) {
+	if len(data) > 0xFF {
+		return nil, ErrMessageTooLong
+	}
+	m := make(Message, 0, 3+len(data))
+	m = append(m, byte(t), byte(len(data)), byte(fn))
+	m = append(m, data...)
+	if err := m.Check(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // Type returns the message type.
 func (m Message) Type() Type {
 	if len(m) == 0 {
@@ -39,6 +71,21 @@ func (m Message) Ok() bool {
 	return m[3] == 0
 }
 
+// ErrorCode returns the error payload of a Reply, zero when the reply
+// is Ok (or the message is not a reply), see ErrorCode for known values.
+func (m Message) ErrorCode() ErrorCode {
+	if len(m) < 4 || m.Type() != Reply {
+		return 0
+	}
+	return ErrorCode(m[3])
+}
+
+// Verify that the trailing checksum (e.g. from a captured frame) is
+// valid for the message (message must not include the checksum).
+func (m Message) Verify(trailingChecksum byte) bool {
+	return Checksum(m) == trailingChecksum
+}
+
 // ReplyOk returns a valid Reply for a Command.
 func (m Message) ReplyOk() Message {
 	if m.Type() == Command {
@@ -47,21 +94,91 @@ func (m Message) ReplyOk() Message {
 	return nil
 }
 
+// String returns a human readable representation of the message, e.g.
+// "Command len=1 Fon data=[0x01]".
+func (m Message) String() string {
+	if len(m) < 3 || len(m) < 3+int(m[1]) {
+		return fmt.Sprintf("Message(%#x)", []byte(m))
+	}
+	return fmt.Sprintf("%v len=%d %v data=[% #x]", m.Type(), m[1], m.Function(), m.Value())
+}
+
+// Format implements fmt.Formatter, %v and %s use String while all
+// other verbs (e.g. %#x) format the raw bytes.
+func (m Message) Format(f fmt.State, verb rune) {
+	switch {
+	case verb == 's', verb == 'v' && !f.Flag('#'):
+		io.WriteString(f, m.String())
+	default:
+		fmt.Fprintf(f, formatDirective(f, verb), []byte(m))
+	}
+}
+
+// formatDirective reconstructs the format directive from the state.
+func formatDirective(f fmt.State, verb rune) string {
+	var b strings.Builder
+	b.WriteByte('%')
+	for _, c := range "+-# 0" {
+		if f.Flag(int(c)) {
+			b.WriteRune(c)
+		}
+	}
+	if w, ok := f.Width(); ok {
+		b.WriteString(strconv.Itoa(w))
+	}
+	if p, ok := f.Precision(); ok {
+		b.WriteByte('.')
+		b.WriteString(strconv.Itoa(p))
+	}
+	b.WriteRune(verb)
+	return b.String()
+}
+
+// DecodedMessage represents the decoded parts of a Message.
+type DecodedMessage struct {
+	Type     Type
+	Function Function
+	Data     []byte
+	// Button is set when the message is a button press command.
+	Button Button
+}
+
+// Decode the message into its parts (message must not include a
+// checksum).
+func (m Message) Decode() (DecodedMessage, error) {
+	err := m.Check()
+	if err != nil {
+		return DecodedMessage{}, err
+	}
+
+	d := DecodedMessage{
+		Type:     m.Type(),
+		Function: m.Function(),
+		Data:     m.Value(),
+	}
+	if d.Type == Command && d.Function == Fbutton && len(d.Data) == 1 {
+		d.Button = Button(d.Data[0])
+	}
+	return d, nil
+}
+
 // Check that the message is valid (message must not include a checksum).
 func (m Message) Check() error {
 	if len(m) < 4 {
-		return errors.New("message too short")
+		return ErrMessageTooShort
 	}
 	if m.Type() != Command && m.Type() != Reply {
-		return errors.New("unknown message type")
+		return ErrUnknownType
 	}
 	if int(m[1])+3 != len(m) {
-		return errors.New("wrong message length")
+		return ErrWrongLength
 	}
 	return nil
 }
 
 // Type represents the message type.
+//
+//go:generate stringer -type=Type
 type Type byte
 
 // LCM message types.
@@ -71,6 +188,8 @@ const (
 )
 
 // Function represents the message function.
+//
+//go:generate stringer -type=Function
 type Function byte
 
 const (
@@ -125,11 +244,44 @@ var (
 	RequestVersion Message = []byte{byte(Command), 0x01, byte(Fversion), 0x01}
 )
 
+// There is no known command for controlling the brightness (or
+// contrast) of the display, lcmd only turns the display on and off. The
+// other functions (0x21, 0x23, 0x25 and 0x26) have not been observed to
+// affect the backlight.
+//
+// DimDisplay and FullBright are provided for discoverability.
+var (
+	// DimDisplay dims the display as far as possible, which means
+	// turning it off (same as DisplayOff).
+	DimDisplay = DisplayOff
+	// FullBright turns the display on at full brightness (same as
+	// DisplayOn).
+	FullBright = DisplayOn
+)
+
 // UnknownCommand0x23, unused. Values come from function arguments.
 //
 // Observed behavior: Nothing.
 var UnknownCommand0x23 Message = []byte{byte(Command), 0x02, 0x23, 0x00, 0x00}
 
+// UnknownCommand0x23Args returns UnknownCommand0x23 with the two
+// (unknown) function arguments set, for experimentation.
+//
+// Observed behavior: Nothing.
+//
+// Function 0x25, which was previously thought to be in the same
+// category, is SetDisplayCharacter (line, column, character).
+func UnknownCommand0x23Args(arg1, arg2 byte) Message {
+	return []byte{byte(Command), 0x02, 0x23, arg1, arg2}
+}
+
+// Custom characters (CGRAM) are not supported. The display is likely
+// driven by a HD44780-class controller, which has 8 user-definable
+// glyphs, but the MCU does not expose a command for programming them:
+// lcmd never defines custom characters and none of the known functions
+// (0x21, 0x23, 0x25 and 0x26) take glyph bitmaps. Character codes 0-7,
+// where custom glyphs would live, can be inspected with CharMap.
+
 // SetClearDisplayPrefix changes the behavior of ClearDisplayPrefix.
 //
 // Known values and behavior of ClearDisplayPrefix:
@@ -166,6 +318,24 @@ var (
 	UnknownReply0x11 Message = []byte{byte(Reply), 0x01, byte(Fon), 0x02}
 )
 
+// ErrorCode represents the (non-zero) payload of an error Reply.
+type ErrorCode byte
+
+// Observed error codes, their exact meaning is unknown.
+const (
+	// ErrorCode0x02 is the most commonly observed error, e.g. as a
+	// reply to the On function (see UnknownReply0x11).
+	ErrorCode0x02 ErrorCode = 0x02
+	// ErrorCode0x04 is the other commonly observed error, the lcmd
+	// binary treats it the same as any other non-zero payload.
+	ErrorCode0x04 ErrorCode = 0x04
+)
+
+// String returns the error code as hex, e.g. "0x04".
+func (c ErrorCode) String() string {
+	return fmt.Sprintf("%#02x", byte(c))
+}
+
 // Button represents a LCM button.
 //
 //go:generate stringer -type=Button
@@ -200,96 +370,206 @@ const (
 //	SetDisplay(DisplayTop, 0, "")
 //	SetDisplay(DisplayTop, 2, "My message")
 func SetDisplay(line DisplayLine, indent int, text string) (raw Message, err error) {
+	return SetDisplayPad(line, indent, text, ' ')
+}
+
+// SetDisplayPad is like SetDisplay, except the text is padded (on the
+// right) to the full width with pad instead of spaces, e.g. to draw a
+// background:
+//
+//	SetDisplayPad(DisplayTop, 0, "CPU ", lcm.Sym.Block)
+func SetDisplayPad(line DisplayLine, indent int, text string, pad byte) (raw Message, err error) {
 	if line != DisplayTop && line != DisplayBottom {
-		return nil, errors.New("display line out of bounds")
+		return nil, ErrBadDisplayLine
 	}
-	if indent > 0xF {
-		return nil, errors.New("indentation out of bounds, [0, 15]")
+	if indent < 0 || indent > 0xF {
+		return nil, ErrIndentOutOfBounds
 	}
 	if len(text) > 16 {
-		return nil, errors.New("text too long")
+		return nil, ErrTextTooLong
 	}
 	if len(text) < 16 {
-		text += strings.Repeat(" ", 16-len(text))
+		text += strings.Repeat(string([]byte{pad}), 16-len(text))
 	}
 
 	raw = append([]byte{byte(Command), 0x12, byte(Ftext), byte(line), byte(indent)}, []byte(text)...)
 	return raw, nil
 }
 
+// ControlCharError is returned by CheckText when the text contains
+// control characters.
+type ControlCharError struct {
+	Positions []int // Byte offsets of the control characters.
+}
+
+func (e *ControlCharError) Error() string {
+	pos := make([]string, 0, len(e.Positions))
+	for _, p := range e.Positions {
+		pos = append(pos, strconv.Itoa(p))
+	}
+	return "control characters at positions " + strings.Join(pos, ", ")
+}
+
+// CheckText returns a *ControlCharError if the text contains control
+// characters (0x00-0x1F and 0x7F). These have no known glyph and could
+// confuse the MCU, they are likely a mistake (e.g. an embedded newline).
+//
+// SetDisplay does not check the text since all character codes are
+// valid for the display (see CharMap), see SetDisplayChecked.
+func CheckText(text string) error {
+	var pos []int
+	for i := 0; i < len(text); i++ {
+		if text[i] < 0x20 || text[i] == 0x7F {
+			pos = append(pos, i)
+		}
+	}
+	if len(pos) > 0 {
+		return &ControlCharError{Positions: pos}
+	}
+	return nil
+}
+
+// SetDisplayChecked is like SetDisplay, except text containing control
+// characters is rejected, see CheckText. Use CharSet.Replace to
+// substitute them instead.
+func SetDisplayChecked(line DisplayLine, indent int, text string) (Message, error) {
+	if err := CheckText(text); err != nil {
+		return nil, err
+	}
+	return SetDisplay(line, indent, text)
+}
+
+// Align specifies the text alignment on a display line.
+type Align int
+
+// Align enums.
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+)
+
+// SetDisplayAligned writes the text on the display line with the given
+// alignment, the remaining cells are filled with spaces. When centering
+// text with an odd number of remaining cells, the extra space is put on
+// the right.
+func SetDisplayAligned(line DisplayLine, align Align, text string) (Message, error) {
+	if len(text) > 16 {
+		return nil, ErrTextTooLong
+	}
+
+	var left int
+	switch align {
+	case AlignLeft:
+	case AlignCenter:
+		left = (16 - len(text)) / 2
+	case AlignRight:
+		left = 16 - len(text)
+	default:
+		return nil, ErrUnknownAlignment
+	}
+
+	return SetDisplay(line, 0, strings.Repeat(" ", left)+text)
+}
+
+// SetDisplayBoth returns the messages for setting the text on both the
+// top and bottom line, each line follows the same rules as SetDisplay.
+func SetDisplayBoth(top, bottom string) ([]Message, error) {
+	t, err := SetDisplay(DisplayTop, 0, top)
+	if err != nil {
+		return nil, fmt.Errorf("top: %w", err)
+	}
+	b, err := SetDisplay(DisplayBottom, 0, bottom)
+	if err != nil {
+		return nil, fmt.Errorf("bottom: %w", err)
+	}
+	return []Message{t, b}, nil
+}
+
+// ClearLine returns the message for blanking a single display line (by
+// filling it with spaces), the other line is left intact. This is the
+// idiomatic way to clear one line, ClearDisplay clears both.
+//
+// Returns nil if the line is out of bounds.
+func ClearLine(line DisplayLine) Message {
+	b, _ := SetDisplay(line, 0, "")
+	return b
+}
+
+// Truncate the text to width runes. When the text is cut an ellipsis
+// ("...") is appended, or a single "." when width is too narrow.
+func Truncate(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+
+	ellipsis := "..."
+	if width < 4 {
+		ellipsis = "."
+	}
+	return cutRunes(s, width-len(ellipsis)) + ellipsis
+}
+
+// cutRunes cuts the string after n runes.
+func cutRunes(s string, n int) string {
+	for i := range s {
+		if n == 0 {
+			return s[:i]
+		}
+		n--
+	}
+	return s
+}
+
+// SetDisplayTrunc writes the text on the display line, text that is too
+// long is truncated instead of returning an error. The text is
+// transliterated first, see Transliterate.
+func SetDisplayTrunc(line DisplayLine, text string) (Message, error) {
+	return SetDisplay(line, 0, Truncate(Transliterate(text), 16))
+}
+
 // SetDisplayCharacter writes a single character onto the display in the
 // specificed location.
 //
 // In lcmd, it is used by Lcmd_User_Menu_Ctl.
 func SetDisplayCharacter(line DisplayLine, column int, char byte) (Message, error) {
 	if line != DisplayTop && line != DisplayBottom {
-		return nil, errors.New("display line out of bounds")
+		return nil, ErrBadDisplayLine
 	}
-	if column > 0xF {
-		return nil, errors.New("column out of bounds, [0, 15]")
+	if column < 0 || column > 0xF {
+		return nil, ErrColumnOutOfBounds
 	}
 	return []byte{byte(Command), 0x03, byte(Fchar), byte(line), byte(column), char}, nil
 }
 
-// Scroll the text on the display. Each invocation of next() will return
-// a message to send. The start value indicates that the text is in the
-// starting position and the done value indicates one rotation has
-// completed. Done becomes true one step before start meaning that the
-// starting position is not yet reached (we have scrolled to the end).
+// ShowAllCharCodes allows all character codes to be shown on the
+// display, one page of 16 codes at a time. Next wraps around to the first
+// page after the last one and goBack makes the next call to next return
+// the previous page.
 //
-//	next := lcm.Scroll(lcm.DisplayTop, "This text will scroll")
-//	for {
-//		b, start, done := next()
-//		send(m, b)
-//		if start {
-//			time.Sleep(2 * time.Second)
-//		} else {
-//			time.Sleep(1 * time.Second)
-//		}
-//		if start && done {
-//			break
-//		}
-//	}
-func Scroll(line DisplayLine, text string) (next func() (raw Message, start, done bool)) {
-	i := 0
-	done := false
-	return func() (Message, bool, bool) {
-		if i >= len(text)-16 {
-			done = true
-		}
-		if i > len(text)-16 {
-			i = 0
-		}
-		start := i == 0
-		trunc := text[i:]
-		if len(trunc) > 16 {
-			trunc = trunc[:16]
-		}
-		i++
-		b, _ := SetDisplay(line, 0, trunc)
-		return b, start, done
-	}
-}
-
-// ShowAllCharCodes allows all character codes to be
+// Deprecated: Use CharMap instead.
 func ShowAllCharCodes() (next func() (line1, line2 Message, start, done bool), goBack func()) {
-	var i uint8
-	chars := make([]byte, 16)
-	done := false
+	c := NewCharMap()
+	shown, back := false, false
 	next = func() (Message, Message, bool, bool) {
-		for j := 0; j < 16; j++ {
-			chars[j] = 1 + i + uint8(j)
-		}
-		line1, _ := SetDisplay(DisplayTop, 0, string(chars))
-		line2, _ := SetDisplay(DisplayBottom, 0, fmt.Sprintf("%03d..........%03d", i, i+15))
-
-		start := i == 0
-		i += 16
-		if i == 0 {
-			done = true
+		switch {
+		case !shown:
+			shown = true
+		case back:
+			c.Prev()
+		default:
+			if !c.Next() {
+				c.First()
+			}
 		}
+		back = false
 
-		return line1, line2, start, done
+		line1, line2 := c.Page()
+		first, last := c.Range()
+		return line1, line2, first == 0, last == 0xFF
 	}
-	return next, func() { i -= 16 * 2 }
+	return next, func() { back = true }
 }
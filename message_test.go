@@ -1,8 +1,11 @@
 package lcm
 
 import (
+	"errors"
 	"fmt"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestSetDisplay(t *testing.T) {
@@ -47,6 +50,11 @@ func TestSetDisplay(t *testing.T) {
 			args:    args{line: DisplayTop, indent: 0xFF, text: "PRESS ANY KEY TO "},
 			wantErr: true,
 		},
+		{
+			name:    "Test negative indent",
+			args:    args{line: DisplayTop, indent: -1, text: "PRESS ANY KEY"},
+			wantErr: true,
+		},
 		{
 			name:    "Test display bottom",
 			args:    args{line: DisplayBottom, indent: 0, text: ">"},
@@ -66,3 +74,316 @@ func TestSetDisplay(t *testing.T) {
 		})
 	}
 }
+
+func TestSetDisplayBoth(t *testing.T) {
+	tests := []struct {
+		name    string
+		top     string
+		bottom  string
+		wantRaw []string
+		wantErr string
+	}{
+		{
+			name:   "Test padding",
+			top:    "PRESS",
+			bottom: "",
+			wantRaw: []string{
+				"0xf01227000050524553532020202020202020202020",
+				"0xf01227010020202020202020202020202020202020",
+			},
+		},
+		{
+			name:   "Test full width",
+			top:    "PRESS ANY KEY TO",
+			bottom: "EXPLODE NOW.....",
+			wantRaw: []string{
+				"0xf012270000505245535320414e59204b455920544f",
+				"0xf0122701004558504c4f4445204e4f572e2e2e2e2e",
+			},
+		},
+		{
+			name:    "Test top too long",
+			top:     "PRESS ANY KEY TO EXPLODE",
+			bottom:  ">",
+			wantErr: "top: text too long",
+		},
+		{
+			name:    "Test bottom too long",
+			top:     ">",
+			bottom:  "PRESS ANY KEY TO EXPLODE",
+			wantErr: "bottom: text too long",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SetDisplayBoth(tt.top, tt.bottom)
+			if tt.wantErr != "" {
+				if !errors.Is(err, ErrTextTooLong) || err.Error() != tt.wantErr {
+					t.Errorf("SetDisplayBoth() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetDisplayBoth() error = %v", err)
+			}
+			if len(got) != len(tt.wantRaw) {
+				t.Fatalf("SetDisplayBoth() = %d messages, want %d", len(got), len(tt.wantRaw))
+			}
+			for i, raw := range got {
+				if fmt.Sprintf("%#x", raw) != tt.wantRaw[i] {
+					t.Errorf("SetDisplayBoth()[%d] = %#x, want %s", i, raw, tt.wantRaw[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSetDisplayPad(t *testing.T) {
+	tests := []struct {
+		text string
+		pad  byte
+		want string
+	}{
+		{"CPU ", '#', "CPU ############"},
+		{"", '-', "----------------"},
+		{"0123456789ABCDEF", '#', "0123456789ABCDEF"},
+		{"Hi", ' ', "Hi              "},
+	}
+	for _, tt := range tests {
+		got, err := SetDisplayPad(DisplayBottom, 0, tt.text, tt.pad)
+		if err != nil {
+			t.Errorf("SetDisplayPad(%q, %q) error = %v", tt.text, tt.pad, err)
+			continue
+		}
+		if string(got[5:]) != tt.want || got[3] != byte(DisplayBottom) {
+			t.Errorf("SetDisplayPad(%q, %q) = %q, want %q", tt.text, tt.pad, got[5:], tt.want)
+		}
+	}
+
+	if _, err := SetDisplayPad(DisplayTop, 0, "12345678901234567", '#'); !errors.Is(err, ErrTextTooLong) {
+		t.Errorf("SetDisplayPad() error = %v, want %v", err, ErrTextTooLong)
+	}
+}
+
+func TestNewMessage(t *testing.T) {
+	setDisplay, _ := SetDisplay(DisplayBottom, 2, "Hello")
+	tests := []struct {
+		name    string
+		want    Message
+		wantErr bool
+	}{
+		{name: "DisplayOn", want: DisplayOn},
+		{name: "DisplayOff", want: DisplayOff},
+		{name: "RequestVersion", want: RequestVersion},
+		{name: "SetDisplay", want: setDisplay},
+		{name: "No data", want: Message{byte(Command), 0x00, byte(Fon)}, wantErr: true},
+		{name: "Unknown type", want: Message{0x00, 0x01, byte(Fon), 0x01}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewMessage(tt.want.Type(), tt.want.Function(), tt.want[3:]...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewMessage() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && fmt.Sprintf("%#x", got) != fmt.Sprintf("%#x", tt.want) {
+				t.Errorf("NewMessage() = %#x, want %#x", got, tt.want)
+			}
+			if err == nil && !got.Verify(Checksum(tt.want)) {
+				t.Errorf("Message.Verify() = false, want true")
+			}
+		})
+	}
+}
+
+func TestSetDisplayAligned(t *testing.T) {
+	type args struct {
+		line  DisplayLine
+		align Align
+		text  string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantRaw string
+		wantErr bool
+	}{
+		{
+			name:    "Test left",
+			args:    args{line: DisplayTop, align: AlignLeft, text: "NAS"},
+			wantRaw: "0xf0122700004e415320202020202020202020202020",
+		},
+		{
+			name:    "Test center",
+			args:    args{line: DisplayTop, align: AlignCenter, text: "NAS"},
+			wantRaw: "0xf0122700002020202020204e415320202020202020",
+		},
+		{
+			name:    "Test center even",
+			args:    args{line: DisplayTop, align: AlignCenter, text: "NAS1"},
+			wantRaw: "0xf0122700002020202020204e415331202020202020",
+		},
+		{
+			name:    "Test right",
+			args:    args{line: DisplayBottom, align: AlignRight, text: "NAS"},
+			wantRaw: "0xf012270100202020202020202020202020204e4153",
+		},
+		{
+			name:    "Test full width",
+			args:    args{line: DisplayTop, align: AlignRight, text: "PRESS ANY KEY TO"},
+			wantRaw: "0xf012270000505245535320414e59204b455920544f",
+		},
+		{
+			name:    "Test text too long",
+			args:    args{line: DisplayTop, align: AlignCenter, text: "PRESS ANY KEY TO EXPLODE"},
+			wantErr: true,
+		},
+		{
+			name:    "Test unknown alignment",
+			args:    args{line: DisplayTop, align: Align(42), text: "NAS"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotRaw, err := SetDisplayAligned(tt.args.line, tt.args.align, tt.args.text)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SetDisplayAligned() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && fmt.Sprintf("%#x", gotRaw) != tt.wantRaw {
+				t.Errorf("SetDisplayAligned() = %#x, want %s", gotRaw, tt.wantRaw)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name  string
+		s     string
+		width int
+		want  string
+	}{
+		{name: "Fits", s: "nas", width: 16, want: "nas"},
+		{name: "Exact", s: "0123456789abcdef", width: 16, want: "0123456789abcdef"},
+		{name: "Ellipsis", s: "my-very-long-hostname", width: 16, want: "my-very-long-..."},
+		{name: "Narrow", s: "hostname", width: 3, want: "ho."},
+		{name: "Zero", s: "hostname", width: 0, want: ""},
+		{name: "Multi-byte", s: "näänäänäänää", width: 5, want: "nä..."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Truncate(tt.s, tt.width); got != tt.want {
+				t.Errorf("Truncate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClearLine(t *testing.T) {
+	tests := []struct {
+		line    DisplayLine
+		wantRaw string
+	}{
+		{line: DisplayTop, wantRaw: "0xf01227000020202020202020202020202020202020"},
+		{line: DisplayBottom, wantRaw: "0xf01227010020202020202020202020202020202020"},
+		{line: DisplayLine(2), wantRaw: ""},
+	}
+	for _, tt := range tests {
+		if got := ClearLine(tt.line); fmt.Sprintf("%#x", got) != tt.wantRaw {
+			t.Errorf("ClearLine(%d) = %#x, want %s", tt.line, got, tt.wantRaw)
+		}
+	}
+}
+
+func TestUnknownCommand0x23Args(t *testing.T) {
+	if diff := cmp.Diff(UnknownCommand0x23, UnknownCommand0x23Args(0, 0)); diff != "" {
+		t.Errorf("UnknownCommand0x23Args(0, 0) (-want +got)\n%s", diff)
+	}
+	if err := UnknownCommand0x23Args(1, 2).Check(); err != nil {
+		t.Errorf("UnknownCommand0x23Args(1, 2).Check() = %v", err)
+	}
+}
+
+func TestMessage_ErrorCode(t *testing.T) {
+	tests := []struct {
+		name string
+		m    Message
+		want ErrorCode
+	}{
+		{"Reply OK", DisplayOn.ReplyOk(), 0},
+		{"Reply error", UnknownReply0x11, ErrorCode0x02},
+		{"Reply error 0x04", Message{byte(Reply), 0x01, byte(Ftext), 0x04}, ErrorCode0x04},
+		{"Command", DisplayOn, 0},
+		{"Empty", nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.m.ErrorCode(); got != tt.want {
+				t.Errorf("ErrorCode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckText(t *testing.T) {
+	tests := []struct {
+		text string
+		want []int
+	}{
+		{"hello world", nil},
+		{"hello\nworld", []int{5}},
+		{"\x00abc\x00", []int{0, 4}},
+		{"tab\there\x7f", []int{3, 8}},
+		{"\xff\x80", nil}, // Not control characters, see CharMap.
+	}
+	for _, tt := range tests {
+		err := CheckText(tt.text)
+		var cerr *ControlCharError
+		if !errors.As(err, &cerr) {
+			if tt.want != nil {
+				t.Errorf("CheckText(%q) = %v, want positions %v", tt.text, err, tt.want)
+			} else if err != nil {
+				t.Errorf("CheckText(%q) = %v, want nil", tt.text, err)
+			}
+			continue
+		}
+		if diff := cmp.Diff(tt.want, cerr.Positions); diff != "" {
+			t.Errorf("CheckText(%q) positions (-want +got)\n%s", tt.text, diff)
+		}
+	}
+
+	if _, err := SetDisplayChecked(DisplayTop, 0, "a\x00b"); err == nil || err.Error() != "control characters at positions 1" {
+		t.Errorf("SetDisplayChecked() err = %v, want control characters at positions 1", err)
+	}
+	if _, err := SetDisplayChecked(DisplayTop, 0, "ok"); err != nil {
+		t.Errorf("SetDisplayChecked() err = %v, want nil", err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	err := func(_ interface{}, err error) error { return err }
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"NewMessage", err(NewMessage(Command, Fon, make([]byte, 256)...)), ErrMessageTooLong},
+		{"Check short", Message{byte(Command), 0x01}.Check(), ErrMessageTooShort},
+		{"Check type", Message{0x00, 0x01, byte(Fon), 0x01}.Check(), ErrUnknownType},
+		{"Check length", Message{byte(Command), 0x02, byte(Fon), 0x01}.Check(), ErrWrongLength},
+		{"SetDisplay line", err(SetDisplay(DisplayLine(2), 0, "")), ErrBadDisplayLine},
+		{"SetDisplay indent", err(SetDisplay(DisplayTop, 16, "")), ErrIndentOutOfBounds},
+		{"SetDisplay text", err(SetDisplay(DisplayTop, 0, "12345678901234567")), ErrTextTooLong},
+		{"SetDisplayBoth", err(SetDisplayBoth("", "12345678901234567")), ErrTextTooLong},
+		{"SetDisplayAligned", err(SetDisplayAligned(DisplayTop, Align(3), "")), ErrUnknownAlignment},
+		{"SetDisplayCharacter", err(SetDisplayCharacter(DisplayTop, 16, 'a')), ErrColumnOutOfBounds},
+		{"ProgressBar", err(ProgressBar(DisplayTop, 0, "BACKUP TO")), ErrTextTooLong},
+	}
+	for _, tt := range tests {
+		if !errors.Is(tt.err, tt.want) {
+			t.Errorf("%s: error = %v, want %v", tt.name, tt.err, tt.want)
+		}
+	}
+}
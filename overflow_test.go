@@ -0,0 +1,44 @@
+package lcm_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mafredri/lcm"
+)
+
+func TestLCM_ReadOverflow(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    lcm.OverflowPolicy
+		wantFirst byte
+	}{
+		{"Drop oldest", lcm.DropOldest, 2},
+		{"Drop newest", lcm.DropNewest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, f := openFake(t, nil, lcm.WithReadOverflow(tt.policy))
+
+			// The receive buffer holds 5 messages.
+			for i := 0; i < 7; i++ {
+				f.PushVersion(0, 0, byte(i))
+			}
+
+			deadline := time.Now().Add(time.Second)
+			for m.Stats().Dropped < 2 {
+				if time.Now().After(deadline) {
+					t.Fatalf("Stats().Dropped = %d, want 2", m.Stats().Dropped)
+				}
+				time.Sleep(time.Millisecond)
+			}
+
+			for i := 0; i < 5; i++ {
+				got := m.Recv().Value()[2]
+				if want := tt.wantFirst + byte(i); got != want {
+					t.Errorf("Recv() #%d patch = %d, want %d", i, got, want)
+				}
+			}
+		})
+	}
+}
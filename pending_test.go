@@ -0,0 +1,53 @@
+package lcm_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mafredri/lcm"
+	"github.com/mafredri/lcm/lcmtest"
+)
+
+func TestLCM_Pending(t *testing.T) {
+	waiting := make(chan struct{})
+	release := make(chan struct{})
+	m, _ := openFake(t, func(msg lcm.Message) []byte {
+		if msg.Function() == lcm.Fon {
+			// Hold the write in flight until released.
+			close(waiting)
+			<-release
+		}
+		return lcmtest.ReplyOk(msg)
+	})
+
+	if n := m.Pending(); n != 0 {
+		t.Errorf("Pending() = %d, want 0", n)
+	}
+
+	errc := make(chan error, 3)
+	go func() { errc <- m.Send(lcm.DisplayOn) }()
+	<-waiting
+	go func() { errc <- m.Send(lcm.DisplayStatus) }()
+	go func() { errc <- m.Send(lcm.DisplayStatus) }()
+
+	deadline := time.Now().Add(time.Second)
+	for m.Pending() != 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Pending() = %d, want 3", m.Pending())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if n := m.Stats().Pending; n != 3 {
+		t.Errorf("Stats().Pending = %d, want 3", n)
+	}
+
+	close(release)
+	for i := 0; i < 3; i++ {
+		if err := <-errc; err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+	if n := m.Pending(); n != 0 {
+		t.Errorf("Pending() after sends = %d, want 0", n)
+	}
+}
@@ -0,0 +1,32 @@
+package lcm_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mafredri/lcm"
+)
+
+func TestLCM_Play(t *testing.T) {
+	m, f := openFake(t, nil)
+
+	frames := []lcm.Frame{
+		{Messages: []lcm.Message{lcm.DisplayOn}},
+		{Delay: 10 * time.Millisecond}, // Delay only.
+		{Messages: []lcm.Message{lcm.ClearDisplay}},
+	}
+	start := time.Now()
+	if err := m.Play(context.Background(), frames); err != nil {
+		t.Fatalf("Play() error = %v", err)
+	}
+	if d := time.Since(start); d < 10*time.Millisecond {
+		t.Errorf("Play() took %v, want at least 10ms", d)
+	}
+
+	want := []lcm.Message{lcm.DisplayOn, lcm.ClearDisplay}
+	if got := f.Received(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Received() = %v, want %v", got, want)
+	}
+}
@@ -1,7 +1,11 @@
 package lcm
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/warthog618/gpiod"
@@ -16,69 +20,261 @@ const (
 	lcmPowerToggleTime   = 250 * time.Millisecond
 )
 
+// dmiProductNamePath is used to detect the NAS model.
+var dmiProductNamePath = "/sys/class/dmi/id/product_name"
+
+type powerPin struct {
+	chip string // Chip label.
+	pin  int
+}
+
+// defaultPowerPin is used for models not in powerPins.
+var defaultPowerPin = powerPin{chip: it87ChipLabel, pin: it87LCMPowerPin}
+
+// powerPins maps the NAS model (normalized, see normalizeModel) to the
+// GPIO pin controlling the LCM power. Only verified models are listed.
+var powerPins = map[string]powerPin{
+	"AS604T":  {chip: it87ChipLabel, pin: it87LCMPowerPin},
+	"AS6204T": {chip: it87ChipLabel, pin: it87LCMPowerPin},
+}
+
+// normalizeModel normalizes the model name, e.g. "AS-604T" => "AS604T".
+func normalizeModel(model string) string {
+	model = strings.ToUpper(strings.TrimSpace(model))
+	return strings.NewReplacer("-", "", " ", "").Replace(model)
+}
+
+// lookupPowerPin returns the power pin for the model, ok is false if the
+// model is unknown (defaultPowerPin is returned).
+func lookupPowerPin(model string) (pin powerPin, ok bool) {
+	pin, ok = powerPins[normalizeModel(model)]
+	if !ok {
+		return defaultPowerPin, false
+	}
+	return pin, true
+}
+
+// detectModel returns the NAS model via DMI.
+func detectModel() (string, error) {
+	b, err := os.ReadFile(dmiProductNamePath)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// powerLine is the GPIO line controlling the LCM power.
+type powerLine interface {
+	Value() (int, error)
+	SetValue(v int) error
+	Close() error
+}
+
 // Power management via GPIO line.
 type Power struct {
-	chip *gpiod.Chip
-	line *gpiod.Line
+	line    powerLine
+	backend string
 }
 
-// On turns the LCM on.
-func (p *Power) On() {
-	p.line.SetValue(1)
+// Backend returns the GPIO backend in use, "chardev" (gpiod) or
+// "sysfs".
+func (p *Power) Backend() string {
+	return p.backend
 }
 
-// Off turns the LCM off.
-func (p *Power) Off() {
-	p.line.SetValue(0)
+// State returns true if the LCM is powered on.
+func (p *Power) State() (bool, error) {
+	v, err := p.line.Value()
+	if err != nil {
+		return false, fmt.Errorf("read gpio line: %w", err)
+	}
+	return v == 1, nil
+}
+
+// On turns the LCM on, it is a no-op if the LCM is already on.
+func (p *Power) On() error {
+	return p.set(true)
+}
+
+// Off turns the LCM off, it is a no-op if the LCM is already off.
+func (p *Power) Off() error {
+	return p.set(false)
+}
+
+func (p *Power) set(on bool) error {
+	cur, err := p.State()
+	if err != nil {
+		return err
+	}
+	if cur == on {
+		return nil
+	}
+	v := 0
+	if on {
+		v = 1
+	}
+	err = p.line.SetValue(v)
+	if err != nil {
+		return fmt.Errorf("set gpio line: %w", err)
+	}
+	return nil
 }
 
 // Cycle the LCM power and return a channel that blocks until initial
-// animation is completed.
+// animation is completed. Errors are ignored, use CycleContext to
+// handle them.
 func (p *Power) Cycle() (initialAnimationComplete <-chan time.Time) {
-	p.Off()
+	_ = p.Off()
 	time.Sleep(lcmPowerToggleTime)
-	p.On()
+	_ = p.On()
 
 	return time.After(lcmPowerOnSettleTime)
 }
 
+// CycleContext cycles the LCM power and waits until the initial
+// animation is completed, or the context is canceled.
+func (p *Power) CycleContext(ctx context.Context) error {
+	err := p.Off()
+	if err != nil {
+		return err
+	}
+	err = sleepContext(ctx, lcmPowerToggleTime)
+	if err != nil {
+		// Don't leave the LCM powered off.
+		if err2 := p.On(); err2 != nil {
+			return err2
+		}
+		return err
+	}
+	err = p.On()
+	if err != nil {
+		return err
+	}
+	return sleepContext(ctx, lcmPowerOnSettleTime)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
 // Close the GPIO line.
 func (p *Power) Close() error {
-	err1 := p.line.Close()
-	err2 := p.chip.Close()
-	if err2 != nil {
-		return err2
+	return p.line.Close()
+}
+
+type powerOptions struct {
+	pin *powerPin
+}
+
+// PowerOption configures Power.
+type PowerOption func(*powerOptions)
+
+// WithPowerPin overrides the GPIO chip (label) and pin used for
+// powering the LCM, by default they are selected based on the NAS
+// model.
+func WithPowerPin(chip string, pin int) PowerOption {
+	return func(o *powerOptions) {
+		o.pin = &powerPin{chip: chip, pin: pin}
 	}
-	return err1
 }
 
-// NewPower initializes the GPIO line for powering LCM on and off.
-func NewPower(consumer string) (*Power, error) {
-	p := &Power{}
+// NewPower initializes the GPIO line for powering LCM on and off. The
+// GPIO chip and pin are selected based on the NAS model (DMI product
+// name), unknown models use the it87 chip (pin 59).
+//
+// The GPIO character device is used when available, otherwise the
+// legacy sysfs interface, see Backend.
+func NewPower(consumer string, opt ...PowerOption) (*Power, error) {
+	var opts powerOptions
+	for _, o := range opt {
+		o(&opts)
+	}
+
+	var model string
+	pin := defaultPowerPin
+	if opts.pin != nil {
+		pin = *opts.pin
+	} else {
+		var err error
+		model, err = detectModel()
+		if err != nil {
+			model = "unknown"
+		}
+		pin, _ = lookupPowerPin(model)
+	}
+
+	gl, err := openGpiodLine(consumer, pin)
+	if err == nil {
+		return &Power{line: gl, backend: "chardev"}, nil
+	}
+	if !errors.Is(err, errChipNotFound) {
+		return nil, err
+	}
+
+	// Older kernels lack the GPIO character device.
+	sl, sysfsErr := openSysfsLine(pin)
+	if sysfsErr == nil {
+		return &Power{line: sl, backend: "sysfs"}, nil
+	}
+	if !errors.Is(sysfsErr, errChipNotFound) {
+		return nil, sysfsErr
+	}
+	if model != "" {
+		return nil, fmt.Errorf("%w (model %q), use WithPowerPin to configure", err, model)
+	}
+	return nil, err
+}
+
+var errChipNotFound = errors.New("gpiochip not found")
 
-	// Find gpiochip representing it87.
+// gpiodLine is a GPIO line via the character device.
+type gpiodLine struct {
+	*gpiod.Line
+	chip *gpiod.Chip
+}
+
+func openGpiodLine(consumer string, pin powerPin) (*gpiodLine, error) {
+	var chip *gpiod.Chip
+
+	// Find gpiochip by label.
 	for _, name := range gpiod.Chips() {
 		c, err := gpiod.NewChip(name, gpiod.WithConsumer(consumer))
 		if err != nil {
 			continue
 		}
-		if c.Label == it87ChipLabel {
-			p.chip = c
+		if c.Label == pin.chip {
+			chip = c
 			break
 		}
 		c.Close()
 	}
 
-	if p.chip == nil {
-		return nil, fmt.Errorf("gpiochip %s not found", it87ChipLabel)
+	if chip == nil {
+		return nil, fmt.Errorf("%w: %s", errChipNotFound, pin.chip)
 	}
 
-	var err error
-	p.line, err = p.chip.RequestLine(it87LCMPowerPin, gpiod.AsOutput(1))
+	line, err := chip.RequestLine(pin.pin, gpiod.AsOutput(1))
 	if err != nil {
-		p.chip.Close()
-		return nil, fmt.Errorf("request gpio line %d failed: %w", it87LCMPowerPin, err)
+		chip.Close()
+		return nil, fmt.Errorf("request gpio line %d failed: %w", pin.pin, err)
 	}
 
-	return p, nil
+	return &gpiodLine{Line: line, chip: chip}, nil
+}
+
+func (l *gpiodLine) Close() error {
+	err1 := l.Line.Close()
+	err2 := l.chip.Close()
+	if err2 != nil {
+		return err2
+	}
+	return err1
 }
@@ -0,0 +1,109 @@
+package lcm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sysfsGPIOPath is the legacy sysfs GPIO interface, used when the GPIO
+// character device is unavailable (older kernels).
+var sysfsGPIOPath = "/sys/class/gpio"
+
+// sysfsLine is a GPIO line via the legacy sysfs interface.
+type sysfsLine struct {
+	gpio     int
+	dir      string
+	exported bool // Exported by us, unexport on close.
+}
+
+func openSysfsLine(pin powerPin) (*sysfsLine, error) {
+	base, err := sysfsChipBase(pin.chip)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &sysfsLine{
+		gpio: base + pin.pin,
+	}
+	l.dir = filepath.Join(sysfsGPIOPath, fmt.Sprintf("gpio%d", l.gpio))
+
+	if _, err := os.Stat(l.dir); os.IsNotExist(err) {
+		err = writeSysfs(filepath.Join(sysfsGPIOPath, "export"), strconv.Itoa(l.gpio))
+		if err != nil {
+			return nil, fmt.Errorf("export gpio %d failed: %w", l.gpio, err)
+		}
+		l.exported = true
+	}
+
+	// Configure as output and set high, like gpiod.AsOutput(1). The
+	// udev rules may take a moment to apply permissions after
+	// export, retry a few times.
+	for i := 0; ; i++ {
+		err = writeSysfs(filepath.Join(l.dir, "direction"), "high")
+		if err == nil || i == 10 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		l.Close()
+		return nil, fmt.Errorf("configure gpio %d failed: %w", l.gpio, err)
+	}
+
+	return l, nil
+}
+
+// sysfsChipBase returns the GPIO number base for the chip (label).
+func sysfsChipBase(label string) (int, error) {
+	chips, err := filepath.Glob(filepath.Join(sysfsGPIOPath, "gpiochip*"))
+	if err != nil {
+		return 0, err
+	}
+	for _, chip := range chips {
+		b, err := os.ReadFile(filepath.Join(chip, "label"))
+		if err != nil || strings.TrimSpace(string(b)) != label {
+			continue
+		}
+		b, err = os.ReadFile(filepath.Join(chip, "base"))
+		if err != nil {
+			return 0, err
+		}
+		return strconv.Atoi(strings.TrimSpace(string(b)))
+	}
+	return 0, fmt.Errorf("%w: %s (sysfs)", errChipNotFound, label)
+}
+
+func (l *sysfsLine) Value() (int, error) {
+	b, err := os.ReadFile(filepath.Join(l.dir, "value"))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(b)))
+}
+
+func (l *sysfsLine) SetValue(v int) error {
+	return writeSysfs(filepath.Join(l.dir, "value"), strconv.Itoa(v))
+}
+
+func (l *sysfsLine) Close() error {
+	if !l.exported {
+		return nil
+	}
+	return writeSysfs(filepath.Join(sysfsGPIOPath, "unexport"), strconv.Itoa(l.gpio))
+}
+
+func writeSysfs(name, value string) error {
+	f, err := os.OpenFile(name, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteString(value)
+	if err2 := f.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
@@ -0,0 +1,59 @@
+package lcm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_sysfsLine(t *testing.T) {
+	dir := t.TempDir()
+	old := sysfsGPIOPath
+	sysfsGPIOPath = dir
+	defer func() { sysfsGPIOPath = old }()
+
+	write := func(name, value string) {
+		t.Helper()
+		name = filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(value), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("gpiochip0/label", "other\n")
+	write("gpiochip0/base", "0\n")
+	write("gpiochip200/label", it87ChipLabel+"\n")
+	write("gpiochip200/base", "200\n")
+	// Already exported (a real export would create the directory).
+	write("gpio259/direction", "in\n")
+	write("gpio259/value", "0\n")
+
+	l, err := openSysfsLine(powerPin{chip: it87ChipLabel, pin: 59})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	if l.gpio != 259 {
+		t.Errorf("gpio = %d, want 259", l.gpio)
+	}
+	b, _ := os.ReadFile(filepath.Join(dir, "gpio259/direction"))
+	if string(b) != "high" {
+		t.Errorf("direction = %q, want %q", b, "high")
+	}
+
+	if err = l.SetValue(1); err != nil {
+		t.Fatal(err)
+	}
+	v, err := l.Value()
+	if err != nil || v != 1 {
+		t.Errorf("Value() = %d, %v; want 1, nil", v, err)
+	}
+
+	_, err = openSysfsLine(powerPin{chip: "missing", pin: 1})
+	if err == nil {
+		t.Error("openSysfsLine() with missing chip succeeded")
+	}
+}
@@ -0,0 +1,25 @@
+package lcm
+
+import "testing"
+
+func Test_lookupPowerPin(t *testing.T) {
+	tests := []struct {
+		model  string
+		want   powerPin
+		wantOk bool
+	}{
+		{"AS-604T", powerPin{chip: it87ChipLabel, pin: it87LCMPowerPin}, true},
+		{"as6204t\n", powerPin{chip: it87ChipLabel, pin: it87LCMPowerPin}, true},
+		{"AS 6204T", powerPin{chip: it87ChipLabel, pin: it87LCMPowerPin}, true},
+		{"AS-9999", defaultPowerPin, false},
+		{"", defaultPowerPin, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			got, ok := lookupPowerPin(tt.model)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("lookupPowerPin() = %v, %v; want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
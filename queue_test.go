@@ -0,0 +1,107 @@
+package lcm_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mafredri/lcm"
+	"github.com/mafredri/lcm/lcmtest"
+)
+
+// TestLCM_SendOrder verifies that concurrent sends are written in the
+// order Send was called.
+func TestLCM_SendOrder(t *testing.T) {
+	const n = 100
+
+	release := make(chan struct{})
+	m, f := openFake(t, func(msg lcm.Message) []byte {
+		<-release // Hold the first write until all sends are queued.
+		return lcmtest.ReplyOk(msg)
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		msg, err := lcm.SetDisplay(lcm.DisplayTop, 0, fmt.Sprint(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		go func() {
+			defer wg.Done()
+			if err := m.Send(msg); err != nil {
+				t.Error(err)
+			}
+		}()
+
+		// Wait for the send to take its place in the queue before
+		// calling the next one.
+		deadline := time.Now().Add(time.Second)
+		for lcm.Tickets(m) != uint64(i+1) {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for send %d to be queued", i)
+			}
+			time.Sleep(10 * time.Microsecond)
+		}
+	}
+	close(release)
+	wg.Wait()
+
+	got := f.Received()
+	if len(got) != n {
+		t.Fatalf("received %d messages, want %d", len(got), n)
+	}
+	for i, msg := range got {
+		want, _ := lcm.SetDisplay(lcm.DisplayTop, 0, fmt.Sprint(i))
+		if string(msg) != string(want) {
+			t.Errorf("message %d = %q, want %q", i, msg[5:], want[5:])
+		}
+	}
+}
+
+func TestLCM_TrySend(t *testing.T) {
+	release := make(chan struct{})
+	m, f := openFake(t, func(msg lcm.Message) []byte {
+		<-release
+		return lcmtest.ReplyOk(msg)
+	})
+
+	// Fill the queue, one write in progress and two buffered.
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := m.Send(lcm.ClearDisplay); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	deadline := time.Now().Add(time.Second)
+	for lcm.Queued(m) != 3 {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for sends to be queued")
+		}
+		time.Sleep(10 * time.Microsecond)
+	}
+
+	ok, err := m.TrySend(lcm.DisplayOn)
+	if ok || err != nil {
+		t.Errorf("TrySend() with full queue = %v, %v, want false, nil", ok, err)
+	}
+
+	close(release)
+	wg.Wait()
+
+	ok, err = m.TrySend(lcm.DisplayOn)
+	if !ok || err != nil {
+		t.Errorf("TrySend() = %v, %v, want true, nil", ok, err)
+	}
+
+	f.SetReplyFunc(lcmtest.NoReply)
+	ok, err = m.TrySend(lcm.DisplayOn, lcm.WithRetryLimit(1), lcm.WithReplyTimeout(time.Millisecond))
+	if !ok || err == nil {
+		t.Errorf("TrySend() without reply = %v, %v, want true, error", ok, err)
+	}
+}
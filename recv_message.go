@@ -80,6 +80,11 @@ func (m *recvMessage) Reset() {
 	m.len = 0
 }
 
+// copyBytes copies bytes from src to dst until dst has a complete
+// frame. The recvMessage signals a complete (valid) frame by returning
+// io.EOF from WriteByte, which is translated into a nil error here. Any
+// other error is returned as-is, including io.EOF from src (i.e. the
+// input ended before the frame was complete).
 func copyBytes(dst io.ByteWriter, src io.ByteReader) error {
 	for {
 		c, err := src.ReadByte()
@@ -95,3 +100,34 @@ func copyBytes(dst io.ByteWriter, src io.ByteReader) error {
 		}
 	}
 }
+
+// resyncReader is a byte reader that supports re-reading the bytes of a
+// corrupt frame, see resync.
+type resyncReader struct {
+	r       io.ByteReader
+	pending []byte
+}
+
+var _ io.ByteReader = (*resyncReader)(nil)
+
+func (r *resyncReader) ReadByte() (byte, error) {
+	if len(r.pending) > 0 {
+		c := r.pending[0]
+		r.pending = r.pending[1:]
+		return c, nil
+	}
+	return r.r.ReadByte()
+}
+
+// resync is called with the bytes of a corrupt frame (b). A corrupt
+// frame may have consumed the start of the next valid frame, so the
+// bytes from the next plausible frame start (Command or Reply) are read
+// again. The first byte is always dropped so progress is guaranteed.
+func (r *resyncReader) resync(b []byte) {
+	for i := 1; i < len(b); i++ {
+		if t := Type(b[i]); t == Command || t == Reply {
+			r.pending = append(append([]byte(nil), b[i:]...), r.pending...)
+			return
+		}
+	}
+}
@@ -2,6 +2,7 @@ package lcm
 
 import (
 	"bytes"
+	"io"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -34,6 +35,37 @@ func Test_recvMessage_WriteByte(t *testing.T) {
 			args:    args{b: []byte{0xf1, 0x01, 0x12, 0x00, 0x00}},
 			wantErr: true,
 		},
+		{
+			name: "Button command",
+			args: args{b: []byte{0xf0, 0x01, 0x80, 0x01, 0x72}},
+			want: &want{
+				sum: 0x72,
+				len: 4,
+			},
+		},
+		{
+			name: "Version command",
+			args: args{b: []byte{0xf0, 0x03, 0x13, 0x00, 0x01, 0x02, 0x09}},
+			want: &want{
+				sum: 0x09,
+				len: 6,
+			},
+		},
+		{
+			name:    "Corrupt double reply",
+			args:    args{b: []byte{0xf1, 0x01, 0xf1, 0x01, 0x12, 0x00, 0x04}},
+			wantErr: true,
+		},
+		{
+			name:    "Reply too long",
+			args:    args{b: []byte{0xf1, 0x03, 0x13, 0x00, 0x01, 0x02, 0x0a}},
+			wantErr: true,
+		},
+		{
+			name:    "Command too long",
+			args:    args{b: []byte{0xf0, 0x11}},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -55,3 +87,30 @@ func Test_recvMessage_WriteByte(t *testing.T) {
 		})
 	}
 }
+
+func Test_copyBytes_multipleFrames(t *testing.T) {
+	frames := [][]byte{
+		{0xf1, 0x01, 0x12, 0x00, 0x04},
+		{0xf0, 0x01, 0x80, 0x01, 0x72},
+		{0xf0, 0x03, 0x13, 0x00, 0x01, 0x02, 0x09},
+	}
+	src := bytes.NewBuffer(bytes.Join(frames, nil))
+	// Trailing partial frame.
+	src.Write([]byte{0xf1, 0x01})
+
+	m := &recvMessage{}
+	for i, want := range frames {
+		m.Reset()
+		if err := copyBytes(m, src); err != nil {
+			t.Fatalf("frame %d: copyBytes() error = %v", i, err)
+		}
+		if diff := cmp.Diff(want, m.Bytes()); diff != "" {
+			t.Errorf("frame %d: (-want +got)\n%s", i, diff)
+		}
+	}
+
+	m.Reset()
+	if err := copyBytes(m, src); err != io.EOF {
+		t.Errorf("copyBytes() on partial frame error = %v, want io.EOF", err)
+	}
+}
@@ -0,0 +1,31 @@
+package lcm_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mafredri/lcm"
+)
+
+func TestLCM_RecvContext(t *testing.T) {
+	m, f := openFake(t, nil)
+
+	f.PushVersion(0, 1, 2)
+	got, err := m.RecvContext(context.Background())
+	if err != nil || got.Function() != lcm.Fversion {
+		t.Errorf("RecvContext() = %v, %v, want version", got, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := m.RecvContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("RecvContext() err = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	m.Close()
+	if _, err := m.RecvContext(context.Background()); !errors.Is(err, lcm.ErrClosed) {
+		t.Errorf("RecvContext() after Close err = %v, want %v", err, lcm.ErrClosed)
+	}
+}
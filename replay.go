@@ -0,0 +1,82 @@
+package lcm
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+)
+
+// MessageReader reads messages from a stream of raw serial traffic.
+type MessageReader struct {
+	r   *resyncReader
+	raw recvMessage
+}
+
+// NewMessageReader returns a MessageReader reading from r.
+func NewMessageReader(r io.Reader) *MessageReader {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &MessageReader{r: &resyncReader{r: br}}
+}
+
+// ReadMessage reads the next frame and returns the message (without
+// checksum). Like the LCM reader, corrupt frames are skipped.
+func (mr *MessageReader) ReadMessage() (Message, error) {
+	var parseErr parsingError
+	for {
+		mr.raw.Reset()
+		err := copyBytes(&mr.raw, mr.r)
+		if err != nil {
+			if errors.As(err, &parseErr) {
+				mr.r.resync(mr.raw.Bytes())
+				continue
+			}
+			return nil, err
+		}
+
+		b := mr.raw.Bytes()
+		return Message(b[:len(b)-1]), nil
+	}
+}
+
+// ReadMessage reads the next frame from r and returns the message
+// (without checksum). Like the LCM reader, corrupt frames are skipped.
+// Bytes read ahead while resyncing after a corrupt frame are lost when
+// it returns, use MessageReader to read a stream of messages.
+func ReadMessage(r io.ByteReader) (Message, error) {
+	mr := &MessageReader{r: &resyncReader{r: r}}
+	return mr.ReadMessage()
+}
+
+// Replay frames the raw serial traffic read from r (e.g. captured by
+// lcm-monitor -raw) and returns the messages (without checksum). Like the
+// LCM reader, corrupt frames are skipped. Partial trailing bytes are
+// discarded.
+func Replay(r io.Reader) ([]Message, error) {
+	var msgs []Message
+	mr := NewMessageReader(r)
+	for {
+		msg, err := mr.ReadMessage()
+		if err != nil {
+			if err == io.EOF {
+				return msgs, nil
+			}
+			return msgs, err
+		}
+		msgs = append(msgs, msg)
+	}
+}
+
+// ReplayFile is like Replay but reads the capture from a file.
+func ReplayFile(path string) ([]Message, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Replay(f)
+}
@@ -0,0 +1,58 @@
+package lcm
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReplay(t *testing.T) {
+	on := []byte{0xf0, 0x01, 0x11, 0x01, 0x03}
+	onReply := []byte{0xf1, 0x01, 0x11, 0x00, 0x03}
+	button := []byte{0xf0, 0x01, 0x80, 0x01, 0x72}
+
+	join := func(b ...[]byte) []byte { return bytes.Join(b, nil) }
+
+	tests := []struct {
+		name string
+		data []byte
+		want []Message
+	}{
+		{"Empty", nil, nil},
+		{"Messages", join(on, onReply, button), []Message{on[:4], onReply[:4], button[:4]}},
+		{"Leading garbage", join([]byte{0x00, 0x42}, on), []Message{on[:4]}},
+		{"Corrupt checksum", join(on[:4], []byte{0x00}, button), []Message{button[:4]}},
+		{"Partial trailing", join(on, button[:3]), []Message{on[:4]}},
+		{"Resync after corrupt frame", join([]byte{0xf0, 0x01, 0x11}, button, on), []Message{button[:4], on[:4]}},
+		{"Resync after truncated reply", join(onReply[:2], onReply, button), []Message{onReply[:4], button[:4]}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Replay(bytes.NewReader(tt.data))
+			if err != nil {
+				t.Fatalf("Replay() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Replay() (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReadMessage(t *testing.T) {
+	button := []byte{0xf0, 0x01, 0x80, 0x01, 0x72}
+	r := bytes.NewReader(append([]byte{0x00, 0xf0, 0x01, 0x11}, button...))
+
+	got, err := ReadMessage(r)
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+	if diff := cmp.Diff(Message(button[:4]), got); diff != "" {
+		t.Errorf("ReadMessage() (-want +got)\n%s", diff)
+	}
+	if _, err := ReadMessage(r); err != io.EOF {
+		t.Errorf("ReadMessage() at end error = %v, want %v", err, io.EOF)
+	}
+}
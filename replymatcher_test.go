@@ -0,0 +1,37 @@
+package lcm_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mafredri/lcm"
+)
+
+func TestLCM_WithReplyMatcher(t *testing.T) {
+	// A display that replies without echoing the function.
+	reply := func(msg lcm.Message) []byte {
+		return withChecksum(lcm.Message{byte(lcm.Reply), 0x01, 0x00, 0x00})
+	}
+	anyReply := func(sent, recv lcm.Message) (done, ok bool) {
+		return recv.Type() == lcm.Reply, recv.Ok()
+	}
+
+	tests := []struct {
+		name    string
+		opts    []lcm.OpenOption
+		wantErr bool
+	}{
+		{"Default", nil, true},
+		{"Matcher", []lcm.OpenOption{lcm.WithReplyMatcher(anyReply)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := openFake(t, reply, tt.opts...)
+
+			_, err := m.TrySend(lcm.DisplayOn, lcm.WithRetryLimit(1), lcm.WithReplyTimeout(10*time.Millisecond))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("TrySend() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
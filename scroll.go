@@ -0,0 +1,314 @@
+package lcm
+
+import (
+	"bytes"
+	"strings"
+	"time"
+)
+
+// Scroll the text on the display. Each invocation of next() will return
+// a message to send. The start value indicates that the text is in the
+// starting position and the done value indicates one rotation has
+// completed. Done becomes true one step before start meaning that the
+// starting position is not yet reached (we have scrolled to the end).
+//
+//	next := lcm.Scroll(lcm.DisplayTop, "This text will scroll")
+//	for {
+//		b, start, done := next()
+//		send(m, b)
+//		if start {
+//			time.Sleep(2 * time.Second)
+//		} else {
+//			time.Sleep(1 * time.Second)
+//		}
+//		if start && done {
+//			break
+//		}
+//	}
+func Scroll(line DisplayLine, text string) (next func() (raw Message, start, done bool)) {
+	return NewScroller(line, text).Next
+}
+
+const (
+	// DefaultScrollDelay is the suggested delay between scroll steps.
+	DefaultScrollDelay = 500 * time.Millisecond
+	// DefaultScrollPause is the suggested delay when the text is in
+	// the starting position.
+	DefaultScrollPause = 2 * time.Second
+)
+
+// Scroller scrolls the text on the display, see Scroll.
+type Scroller struct {
+	line  DisplayLine
+	text  string
+	buf   string // Text with gap, when gap is used.
+	step  int
+	gap   int
+	delay time.Duration
+	pause time.Duration
+	i     int
+	done  bool
+}
+
+// ScrollOption configures the Scroller.
+type ScrollOption func(*Scroller)
+
+// WithStep sets the number of characters advanced per step (default 1).
+func WithStep(n int) ScrollOption {
+	return func(s *Scroller) {
+		if n < 1 {
+			n = 1
+		}
+		s.step = n
+	}
+}
+
+// WithGap enables marquee-like scrolling where the text wraps around
+// with n spaces in between the end and the beginning of the text. By
+// default (zero gap) the text scrolls until the end is visible and then
+// jumps back to the start.
+func WithGap(n int) ScrollOption {
+	return func(s *Scroller) {
+		if n < 0 {
+			n = 0
+		}
+		s.gap = n
+	}
+}
+
+// WithScrollDelay sets the suggested delays returned by NextDelay, delay
+// is used between steps and pause when the text is in the starting
+// position.
+func WithScrollDelay(delay, pause time.Duration) ScrollOption {
+	return func(s *Scroller) {
+		s.delay = delay
+		s.pause = pause
+	}
+}
+
+// NewScroller returns a new Scroller for the text on the display line.
+func NewScroller(line DisplayLine, text string, opts ...ScrollOption) *Scroller {
+	s := &Scroller{
+		line:  line,
+		step:  1,
+		delay: DefaultScrollDelay,
+		pause: DefaultScrollPause,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	s.setText(text)
+	return s
+}
+
+// Next returns the next message to send, see Scroll for the meaning of
+// start and done.
+func (s *Scroller) Next() (raw Message, start, done bool) {
+	// Text fits on the display, nothing to scroll.
+	if len(s.text) <= 16 {
+		s.done = true
+		raw, _ = SetDisplay(s.line, 0, s.text)
+		return raw, true, true
+	}
+
+	start = s.i == 0
+	if s.gap > 0 {
+		raw, _ = SetDisplay(s.line, 0, s.window())
+		s.i += s.step
+		if s.i >= len(s.buf) {
+			s.done = true
+			s.i = 0
+		}
+		return raw, start, s.done
+	}
+
+	last := len(s.text) - 16
+	raw, _ = SetDisplay(s.line, 0, s.text[s.i:s.i+16])
+	if s.i == last {
+		s.done = true
+		s.i = 0
+	} else {
+		s.i += s.step
+		if s.i > last {
+			s.i = last
+		}
+	}
+	return raw, start, s.done
+}
+
+// window returns the visible part of the text (with gap) wrapping
+// around to the beginning when necessary.
+func (s *Scroller) window() string {
+	if s.i+16 <= len(s.buf) {
+		return s.buf[s.i : s.i+16]
+	}
+	return s.buf[s.i:] + s.buf[:16-(len(s.buf)-s.i)]
+}
+
+// NextDelay returns the next message to send along with the suggested
+// delay before the following message should be sent.
+func (s *Scroller) NextDelay() (raw Message, delay time.Duration) {
+	raw, start, _ := s.Next()
+	if start {
+		return raw, s.pause
+	}
+	return raw, s.delay
+}
+
+// Reset the scroller to the starting position.
+func (s *Scroller) Reset() {
+	s.i = 0
+	s.done = false
+}
+
+// SetText changes the text and resets the scroller, unless the text is
+// unchanged in which case the position is kept.
+func (s *Scroller) SetText(text string) {
+	if text == s.text {
+		return
+	}
+	s.setText(text)
+	s.Reset()
+}
+
+func (s *Scroller) setText(text string) {
+	s.text = text
+	s.buf = text
+	if s.gap > 0 {
+		s.buf = text + strings.Repeat(" ", s.gap)
+	}
+}
+
+// Text returns the current text.
+func (s *Scroller) Text() string {
+	return s.text
+}
+
+// Ticker returns a function that scrolls the text on the display line
+// continuously, one character per call, like a stock ticker. Unlike
+// Scroll there is no pause, the text wraps around through gap spaces
+// and loops seamlessly (even when the text fits on the display).
+// Returns nil messages if the line is out of bounds.
+//
+//	next := lcm.Ticker(lcm.DisplayBottom, "NAS: OK  DISKS: OK", 4)
+//	for range time.Tick(300 * time.Millisecond) {
+//		send(m, next())
+//	}
+//
+// Each call allocates only the returned message.
+func Ticker(line DisplayLine, text string, gap int) func() Message {
+	if gap < 0 {
+		gap = 0
+	}
+	blank := ClearLine(line)
+	loop := text + strings.Repeat(" ", gap)
+	if blank == nil || loop == "" {
+		return func() Message { return ClearLine(line) }
+	}
+	header := blank[:len(blank)-16]
+
+	// Repeat the loop so that every window is a plain slice.
+	n := len(loop)
+	ring := strings.Repeat(loop, (n+16+n-1)/n)
+
+	i := 0
+	return func() Message {
+		raw := make(Message, len(header)+16)
+		copy(raw, header)
+		copy(raw[len(header):], ring[i:i+16])
+		i = (i + 1) % n
+		return raw
+	}
+}
+
+// Blink returns the message for the text when on is true and a blank
+// line otherwise. Toggling on from a ticker makes the text blink:
+//
+//	t := time.NewTicker(500 * time.Millisecond)
+//	defer t.Stop()
+//	on := true
+//	for range t.C {
+//		b, _ := lcm.Blink(lcm.DisplayBottom, "DISK FAILING", on)
+//		send(m, b)
+//		on = !on
+//	}
+//
+// The text is validated even when on is false.
+func Blink(line DisplayLine, text string, on bool) (Message, error) {
+	b, err := SetDisplay(line, 0, text)
+	if err != nil {
+		return nil, err
+	}
+	if !on {
+		return ClearLine(line), nil
+	}
+	return b, nil
+}
+
+// MultiScroller drives a Scroller for each display line, allowing them
+// to scroll independently at different speeds.
+//
+//	ms := lcm.NewMultiScroller(
+//		lcm.NewScroller(lcm.DisplayTop, hostname),
+//		lcm.NewScroller(lcm.DisplayBottom, status, lcm.WithScrollDelay(250*time.Millisecond, time.Second)),
+//	)
+//	for {
+//		msgs, delay := ms.Next()
+//		for _, b := range msgs {
+//			send(m, b)
+//		}
+//		time.Sleep(delay)
+//	}
+type MultiScroller struct {
+	s    [2]*Scroller
+	wait [2]time.Duration
+	last [2]Message
+}
+
+// NewMultiScroller returns a new MultiScroller for the top and bottom
+// line scrollers.
+func NewMultiScroller(top, bottom *Scroller) *MultiScroller {
+	return &MultiScroller{s: [2]*Scroller{top, bottom}}
+}
+
+// Top returns the Scroller for the top line.
+func (ms *MultiScroller) Top() *Scroller { return ms.s[0] }
+
+// Bottom returns the Scroller for the bottom line.
+func (ms *MultiScroller) Bottom() *Scroller { return ms.s[1] }
+
+// Next returns the messages for the lines that are due to advance and
+// the delay until Next should be called again. A line is skipped when
+// its content has not changed since the last call.
+func (ms *MultiScroller) Next() (msgs []Message, delay time.Duration) {
+	for i, s := range ms.s {
+		if ms.wait[i] > 0 {
+			continue
+		}
+		var raw Message
+		raw, ms.wait[i] = s.NextDelay()
+		if !bytes.Equal(raw, ms.last[i]) {
+			msgs = append(msgs, raw)
+			ms.last[i] = raw
+		}
+	}
+
+	delay = ms.wait[0]
+	if ms.wait[1] < delay {
+		delay = ms.wait[1]
+	}
+	for i := range ms.wait {
+		ms.wait[i] -= delay
+	}
+	return msgs, delay
+}
+
+// Reset both scrollers to their starting positions, the next call to
+// Next will return messages for both lines.
+func (ms *MultiScroller) Reset() {
+	for i, s := range ms.s {
+		s.Reset()
+		ms.wait[i] = 0
+		ms.last[i] = nil
+	}
+}
@@ -0,0 +1,238 @@
+package lcm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestScroll(t *testing.T) {
+	type flags struct{ start, done bool }
+	repeat := func(f flags, n int) []flags {
+		var ff []flags
+		for i := 0; i < n; i++ {
+			ff = append(ff, f)
+		}
+		return ff
+	}
+	concat := func(ff ...[]flags) []flags {
+		var all []flags
+		for _, f := range ff {
+			all = append(all, f...)
+		}
+		return all
+	}
+	tests := []struct {
+		name string
+		len  int
+		want []flags
+	}{
+		{name: "Length 0", len: 0, want: repeat(flags{true, true}, 3)},
+		{name: "Length 5", len: 5, want: repeat(flags{true, true}, 3)},
+		{name: "Length 16", len: 16, want: repeat(flags{true, true}, 3)},
+		{name: "Length 17", len: 17, want: []flags{{true, false}, {false, true}, {true, true}, {false, true}}},
+		{
+			name: "Length 40",
+			len:  40,
+			want: concat(
+				[]flags{{true, false}},
+				repeat(flags{false, false}, 23),
+				[]flags{{false, true}, {true, true}},
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := make([]byte, tt.len)
+			for i := range text {
+				text[i] = 'a' + byte(i%26)
+			}
+			next := Scroll(DisplayTop, string(text))
+			for i, want := range tt.want {
+				b, start, done := next()
+				if err := b.Check(); err != nil {
+					t.Fatalf("Scroll() call %d: %v", i, err)
+				}
+				if start != want.start || done != want.done {
+					t.Errorf("Scroll() call %d: start, done = %v, %v, want %v, %v", i, start, done, want.start, want.done)
+				}
+			}
+		})
+	}
+}
+
+func TestScroller_SetText(t *testing.T) {
+	s := NewScroller(DisplayTop, "This text will scroll")
+	s.Next()
+	s.Next()
+	s.SetText("This text will scroll")
+	if _, start, _ := s.Next(); start {
+		t.Errorf("Scroller.Next() start = true after SetText with same text, want false")
+	}
+	s.SetText("Other text that will scroll")
+	if _, start, done := s.Next(); !start || done {
+		t.Errorf("Scroller.Next() start, done = %v, %v after SetText, want true, false", start, done)
+	}
+	s.Next()
+	s.Reset()
+	if _, start, done := s.Next(); !start || done {
+		t.Errorf("Scroller.Next() start, done = %v, %v after Reset, want true, false", start, done)
+	}
+}
+
+func TestScroller_Gap(t *testing.T) {
+	text := "abcdefghijklmnopqr" // 18 characters.
+	s := NewScroller(DisplayTop, text, WithGap(3))
+
+	var got []string
+	for i := 0; i < len(text)+3+1; i++ {
+		b, _, _ := s.Next()
+		got = append(got, string(b[5:]))
+	}
+
+	want := []string{
+		"abcdefghijklmnop",
+		"bcdefghijklmnopq",
+		"cdefghijklmnopqr",
+		"defghijklmnopqr ",
+		"efghijklmnopqr  ",
+		"fghijklmnopqr   ",
+		"ghijklmnopqr   a",
+	}
+	for i, w := range want {
+		if got[i] != w {
+			t.Errorf("Scroller.Next() call %d = %q, want %q", i, got[i], w)
+		}
+	}
+	if last, first := got[len(got)-2], got[len(got)-1]; last != " abcdefghijklmno" || first != "abcdefghijklmnop" {
+		t.Errorf("Scroller.Next() wrap around = %q, %q, want %q, %q", last, first, " abcdefghijklmno", "abcdefghijklmnop")
+	}
+}
+
+func TestScroller_Step(t *testing.T) {
+	text := "abcdefghijklmnopqrst" // 20 characters.
+	s := NewScroller(DisplayTop, text, WithStep(3))
+
+	want := []struct {
+		text        string
+		start, done bool
+	}{
+		{"abcdefghijklmnop", true, false},
+		{"defghijklmnopqrs", false, false},
+		{"efghijklmnopqrst", false, true},
+		{"abcdefghijklmnop", true, true},
+	}
+	for i, w := range want {
+		b, start, done := s.Next()
+		if string(b[5:]) != w.text || start != w.start || done != w.done {
+			t.Errorf("Scroller.Next() call %d = %q, %v, %v, want %q, %v, %v", i, b[5:], start, done, w.text, w.start, w.done)
+		}
+	}
+}
+
+func TestMultiScroller(t *testing.T) {
+	ms := NewMultiScroller(
+		NewScroller(DisplayTop, "abcdefghijklmnopq", WithScrollDelay(time.Second, time.Second)),
+		NewScroller(DisplayBottom, "0123456789012345678", WithScrollDelay(3*time.Second, 3*time.Second)),
+	)
+
+	type step struct {
+		lines []DisplayLine
+		delay time.Duration
+	}
+	want := []step{
+		{lines: []DisplayLine{DisplayTop, DisplayBottom}, delay: time.Second},
+		{lines: []DisplayLine{DisplayTop}, delay: time.Second},
+		{lines: []DisplayLine{DisplayTop}, delay: time.Second},
+		{lines: []DisplayLine{DisplayTop, DisplayBottom}, delay: time.Second},
+	}
+	for i, w := range want {
+		msgs, delay := ms.Next()
+		var lines []DisplayLine
+		for _, b := range msgs {
+			lines = append(lines, DisplayLine(b[3]))
+		}
+		if diff := cmp.Diff(w.lines, lines); diff != "" || delay != w.delay {
+			t.Errorf("MultiScroller.Next() call %d: delay = %v, want %v, lines (-want +got)\n%s", i, delay, w.delay, diff)
+		}
+	}
+}
+
+func TestMultiScroller_Static(t *testing.T) {
+	ms := NewMultiScroller(
+		NewScroller(DisplayTop, "static"),
+		NewScroller(DisplayBottom, "0123456789012345678"),
+	)
+	if msgs, _ := ms.Next(); len(msgs) != 2 {
+		t.Fatalf("MultiScroller.Next() = %d messages, want 2", len(msgs))
+	}
+	for i := 0; i < 10; i++ {
+		msgs, _ := ms.Next()
+		for _, b := range msgs {
+			if DisplayLine(b[3]) == DisplayTop {
+				t.Errorf("MultiScroller.Next() call %d: static top line was resent", i)
+			}
+		}
+	}
+}
+
+func TestBlink(t *testing.T) {
+	on, err := Blink(DisplayBottom, "ALERT", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(on[5:]); got != "ALERT           " {
+		t.Errorf("Blink(on) = %q, want %q", got, "ALERT           ")
+	}
+	off, err := Blink(DisplayBottom, "ALERT", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(ClearLine(DisplayBottom), off); diff != "" {
+		t.Errorf("Blink(off) (-want +got)\n%s", diff)
+	}
+	if _, err = Blink(DisplayBottom, "PRESS ANY KEY TO EXPLODE", false); err == nil {
+		t.Errorf("Blink() error = nil, want error for text too long")
+	}
+}
+
+func TestTicker(t *testing.T) {
+	text := "0123456789ABCDEFGHIJ" // 20 characters.
+	gap := 3
+	next := Ticker(DisplayTop, text, gap)
+
+	loop := text + "   "
+	var got []string
+	for i := 0; i < 2*len(loop)+1; i++ {
+		got = append(got, string(next()[5:]))
+	}
+	for i, w := range got {
+		// Each window is the loop advanced by one character,
+		// continuing through the gap into the beginning.
+		j := i % len(loop)
+		want := (loop + loop)[j : j+16]
+		if w != want {
+			t.Errorf("next() #%d = %q, want %q", i, w, want)
+		}
+	}
+	if got[19] != "J   0123456789AB" {
+		t.Errorf("next() #19 = %q, want %q", got[19], "J   0123456789AB")
+	}
+	if got[len(loop)] != got[0] {
+		t.Errorf("next() did not loop, got %q, want %q", got[len(loop)], got[0])
+	}
+
+	// Short text loops too.
+	next = Ticker(DisplayBottom, "hi", 2)
+	for i, want := range []string{"hi  hi  hi  hi  ", "i  hi  hi  hi  h", "  hi  hi  hi  hi"} {
+		b := next()
+		if b[3] != byte(DisplayBottom) || string(b[5:]) != want {
+			t.Errorf("short next() #%d = %q, want %q", i, b[5:], want)
+		}
+	}
+
+	if got := Ticker(DisplayLine(2), text, gap)(); got != nil {
+		t.Errorf("Ticker(DisplayLine(2)) = %#x, want nil", got)
+	}
+}
@@ -0,0 +1,42 @@
+package lcm_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mafredri/lcm"
+	"github.com/mafredri/lcm/lcmtest"
+)
+
+func TestLCM_SendError(t *testing.T) {
+	tests := []struct {
+		name         string
+		reply        lcmtest.ReplyFunc
+		wantCode     lcm.ErrorCode
+		wantTimedOut bool
+	}{
+		{"Reply error", lcmtest.ReplyError, 0x01, false},
+		{"No reply", lcmtest.NoReply, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := openFake(t, tt.reply)
+
+			_, err := m.TrySend(lcm.DisplayOn, lcm.WithRetryLimit(2), lcm.WithReplyTimeout(10*time.Millisecond))
+			var serr *lcm.SendError
+			if !errors.As(err, &serr) || !errors.Is(err, lcm.ErrRetryLimit) {
+				t.Fatalf("TrySend() error = %v, want *lcm.SendError", err)
+			}
+			if serr.Retries != 2 || serr.RetryLimit != 2 {
+				t.Errorf("Retries = %d/%d, want 2/2", serr.Retries, serr.RetryLimit)
+			}
+			if serr.LastErrorCode != tt.wantCode {
+				t.Errorf("LastErrorCode = %v, want %v", serr.LastErrorCode, tt.wantCode)
+			}
+			if serr.TimedOut != tt.wantTimedOut {
+				t.Errorf("TimedOut = %v, want %v", serr.TimedOut, tt.wantTimedOut)
+			}
+		})
+	}
+}
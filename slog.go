@@ -0,0 +1,44 @@
+//go:build go1.21
+// +build go1.21
+
+package lcm
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// WithSlog sets a structured logger used by LCM (default none), it
+// replaces the logger set by WithLogger. Reads, writes and replies are
+// logged with attributes such as the direction (dir), frame, function
+// (fn), write id and try.
+//
+// Every read and write is logged at the debug level, retries and
+// timeouts at info and failures at warn or error.
+//
+// Requires Go 1.21 or later.
+func WithSlog(l *slog.Logger) OpenOption {
+	return func(o *openOptions) {
+		o.l = slogLogger{l: l}
+		o.logAttrs = func(level logLevel, msg string, kv ...interface{}) {
+			l.Log(context.Background(), slogLevels[level], msg, kv...)
+		}
+	}
+}
+
+var slogLevels = map[logLevel]slog.Level{
+	levelDebug: slog.LevelDebug,
+	levelInfo:  slog.LevelInfo,
+	levelWarn:  slog.LevelWarn,
+	levelError: slog.LevelError,
+}
+
+// slogLogger adapts slog to Logger.
+type slogLogger struct {
+	l *slog.Logger
+}
+
+func (s slogLogger) Printf(format string, v ...interface{}) {
+	s.l.Info(fmt.Sprintf(format, v...))
+}
@@ -0,0 +1,53 @@
+//go:build go1.21
+// +build go1.21
+
+package lcm_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mafredri/lcm"
+)
+
+type syncBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.String()
+}
+
+func TestWithSlog(t *testing.T) {
+	var buf syncBuffer
+	l := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	m, _ := openFake(t, nil, lcm.WithSlog(l))
+	if err := m.Send(lcm.DisplayOn); err != nil {
+		t.Fatal(err)
+	}
+	m.Close()
+
+	out := buf.String()
+	for _, want := range []string{
+		"level=DEBUG msg=\"LCM.write: wrote: 0xf001110103 5, err: <nil>\" dir=out frame=0xf001110103",
+		"dir=in frame=0xf101110003 type=Reply fn=Fon",
+		"id=1 fn=Fon try=1",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log output missing %q:\n%s", want, out)
+		}
+	}
+}
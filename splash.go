@@ -0,0 +1,69 @@
+package lcm
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Frame is a single step of an animation, the messages are sent as a
+// batch after which the animation pauses for Delay. A frame without
+// messages only pauses.
+type Frame struct {
+	Messages []Message
+	Delay    time.Duration
+}
+
+const (
+	splashDots      = 3
+	splashDotDelay  = 250 * time.Millisecond
+	splashHoldDelay = time.Second
+)
+
+// SplashSequence returns a short welcome animation, the (centered) name
+// is shown on the top line while dots fill up on the bottom line. The
+// name is transliterated and truncated to fit the display.
+//
+//	err := m.Play(ctx, lcm.SplashSequence("openlcmd"))
+//
+// The frames can be modified (or replaced) to customize the splash.
+func SplashSequence(name string) []Frame {
+	top, _ := SetDisplayAligned(DisplayTop, AlignCenter, Truncate(Transliterate(name), 16))
+	frames := []Frame{{
+		Messages: []Message{top, ClearLine(DisplayBottom)},
+		Delay:    splashDotDelay,
+	}}
+	for i := 1; i <= splashDots; i++ {
+		// Dots are padded to a fixed width so they don't shift
+		// around when centered.
+		dots := strings.Repeat(".", i) + strings.Repeat(" ", splashDots-i)
+		bottom, _ := SetDisplayAligned(DisplayBottom, AlignCenter, dots)
+		frames = append(frames, Frame{
+			Messages: []Message{bottom},
+			Delay:    splashDotDelay,
+		})
+	}
+	frames[len(frames)-1].Delay = splashHoldDelay
+	return frames
+}
+
+// Play the frames on the display, e.g. a SplashSequence. Playback stops
+// when ctx is canceled or a frame fails to send.
+func (m *LCM) Play(ctx context.Context, frames []Frame) error {
+	for _, f := range frames {
+		if len(f.Messages) > 0 {
+			if err := m.SendBatch(f.Messages...); err != nil {
+				return err
+			}
+		}
+
+		t := time.NewTimer(f.Delay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package lcm
+
+import (
+	"testing"
+)
+
+func TestSplashSequence(t *testing.T) {
+	frames := SplashSequence("openlcmd")
+
+	want := []struct {
+		top, bottom string
+	}{
+		{"    openlcmd    ", "                "},
+		{"", "      .         "},
+		{"", "      ..        "},
+		{"", "      ...       "},
+	}
+	if len(frames) != len(want) {
+		t.Fatalf("SplashSequence() = %d frames, want %d", len(frames), len(want))
+	}
+
+	for i, f := range frames {
+		var top, bottom string
+		for _, msg := range f.Messages {
+			if err := msg.Check(); err != nil {
+				t.Errorf("frame %d: Check() = %v", i, err)
+				continue
+			}
+			switch DisplayLine(msg[3]) {
+			case DisplayTop:
+				top = string(msg[5:])
+			case DisplayBottom:
+				bottom = string(msg[5:])
+			}
+		}
+		if top != want[i].top || bottom != want[i].bottom {
+			t.Errorf("frame %d = (%q, %q), want (%q, %q)", i, top, bottom, want[i].top, want[i].bottom)
+		}
+		if f.Delay <= 0 {
+			t.Errorf("frame %d: Delay = %v, want > 0", i, f.Delay)
+		}
+	}
+}
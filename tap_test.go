@@ -0,0 +1,46 @@
+package lcm_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mafredri/lcm"
+)
+
+func TestLCM_WithTap(t *testing.T) {
+	type frame struct {
+		dir   lcm.Direction
+		frame string
+	}
+	var (
+		mu     sync.Mutex
+		frames []frame
+	)
+	tap := func(dir lcm.Direction, m lcm.Message, ts time.Time) {
+		if ts.IsZero() {
+			t.Error("tap: zero timestamp")
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		frames = append(frames, frame{dir, fmt.Sprintf("%#x", []byte(m))})
+	}
+
+	m, _ := openFake(t, nil, lcm.WithTap(tap))
+
+	if err := m.Send(lcm.DisplayOn); err != nil {
+		t.Fatal(err)
+	}
+	// The reply is tapped before it's handled, no need to wait.
+	mu.Lock()
+	defer mu.Unlock()
+
+	want := []frame{
+		{lcm.Out, "0xf001110103"},
+		{lcm.In, "0xf101110003"},
+	}
+	if fmt.Sprint(frames) != fmt.Sprint(want) {
+		t.Errorf("tapped frames = %v, want %v", frames, want)
+	}
+}
@@ -0,0 +1,74 @@
+package lcm
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// TransliterateFallback is used for runes that are not ASCII and have
+// no entry in Transliterations.
+const TransliterateFallback = '?'
+
+// Transliterations maps common Unicode runes to their closest ASCII
+// representation on the display. Since the character table of the MCU
+// is largely unknown, only plain ASCII is used.
+//
+// The table can be modified (or replaced) to customize the behavior of
+// Transliterate.
+var Transliterations = map[rune]string{
+	'À': "A", 'Á': "A", 'Â': "A", 'Ã': "A", 'Ä': "A", 'Å': "A", 'Æ': "AE",
+	'Ç': "C", 'Č': "C",
+	'È': "E", 'É': "E", 'Ê': "E", 'Ë': "E", 'Ě': "E",
+	'Ì': "I", 'Í': "I", 'Î': "I", 'Ï': "I",
+	'Ð': "D", 'Ł': "L", 'Ñ': "N",
+	'Ò': "O", 'Ó': "O", 'Ô': "O", 'Õ': "O", 'Ö': "O", 'Ø': "O", 'Ő': "O", 'Œ': "OE",
+	'Ř': "R", 'Š': "S", 'Þ': "Th",
+	'Ù': "U", 'Ú': "U", 'Û': "U", 'Ü': "U", 'Ű': "U",
+	'Ý': "Y", 'Ž': "Z",
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'æ': "ae",
+	'ç': "c", 'č': "c",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ě': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i",
+	'ð': "d", 'ł': "l", 'ñ': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ő': "o", 'œ': "oe",
+	'ř': "r", 'š': "s", 'ß': "ss", 'þ': "th",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ű': "u",
+	'ý': "y", 'ÿ': "y", 'ž': "z",
+
+	'‘': "'", '’': "'", '‚': "'", '‛': "'", '′': "'",
+	'“': `"`, '”': `"`, '„': `"`, '″': `"`,
+	'«': "<<", '»': ">>",
+	'‐': "-", '‑': "-", '‒': "-", '–': "-", '—': "-", '―': "-",
+	'…': "...", '•': "*", '×': "x", '÷': "/",
+	'↓': "D", '↑': "U", // Download and upload.
+	'\u00a0': " ", // No-break space.
+}
+
+// Transliterate the text into something that can be shown on the
+// display. Runes without a known representation, including control
+// characters (see CheckText), are replaced by TransliterateFallback.
+func Transliterate(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		switch {
+		case r == utf8.RuneError, r < 0x20, r == 0x7F:
+			b.WriteRune(TransliterateFallback)
+		case r < utf8.RuneSelf:
+			b.WriteRune(r)
+		default:
+			if t, ok := Transliterations[r]; ok {
+				b.WriteString(t)
+			} else {
+				b.WriteRune(TransliterateFallback)
+			}
+		}
+	}
+	return b.String()
+}
+
+// SetDisplayText is like SetDisplay, except the text is transliterated
+// first, see Transliterate.
+func SetDisplayText(line DisplayLine, indent int, text string) (Message, error) {
+	return SetDisplay(line, indent, Transliterate(text))
+}
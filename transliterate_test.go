@@ -0,0 +1,27 @@
+package lcm
+
+import "testing"
+
+func TestTransliterate(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{name: "ASCII", s: "nas.local", want: "nas.local"},
+		{name: "Accent", s: "café", want: "cafe"},
+		{name: "Diaeresis", s: "naïve", want: "naive"},
+		{name: "Hostname", s: "mäkinen", want: "makinen"},
+		{name: "Quotes and dash", s: "“hi” — it’s", want: `"hi" - it's`},
+		{name: "Emoji", s: "ok 👍", want: "ok ?"},
+		{name: "Invalid UTF-8", s: "a\xffb", want: "a?b"},
+		{name: "Control characters", s: "a\nb\x00c\x7f", want: "a?b?c?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Transliterate(tt.s); got != tt.want {
+				t.Errorf("Transliterate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package lcm_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mafredri/lcm"
+	"github.com/mafredri/lcm/lcmtest"
+)
+
+func TestLCM_Version(t *testing.T) {
+	m, f := openFake(t, lcmtest.ReplyVersion(0, 1, 2))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	for i := 0; i < 2; i++ {
+		major, minor, patch, err := m.Version(ctx)
+		if err != nil {
+			t.Fatalf("Version() error = %v", err)
+		}
+		if major != 0 || minor != 1 || patch != 2 {
+			t.Errorf("Version() = %d.%d.%d, want 0.1.2", major, minor, patch)
+		}
+	}
+
+	var requests int
+	for _, msg := range f.Received() {
+		if msg.Function() == lcm.Fversion {
+			requests++
+		}
+	}
+	if requests != 1 {
+		t.Errorf("version requested %d times, want 1 (cached)", requests)
+	}
+}
+
+func TestLCM_VersionCanceled(t *testing.T) {
+	requested := make(chan struct{}, 1)
+	release := make(chan struct{})
+	var requests int32
+	m, _ := openFake(t, func(msg lcm.Message) []byte {
+		if msg.Function() == lcm.Fversion && atomic.AddInt32(&requests, 1) == 1 {
+			// Hold the first request and only ack it.
+			requested <- struct{}{}
+			<-release
+			return lcmtest.ReplyOk(msg)
+		}
+		return lcmtest.ReplyVersion(0, 1, 2)(msg)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, _, _, err := m.Version(ctx)
+		first <- err
+	}()
+	<-requested
+
+	second := make(chan error, 1)
+	go func() {
+		_, _, _, err := m.Version(context.Background())
+		second <- err
+	}()
+	// Let the second caller wait for the first request, there is no
+	// event to sync on. Without waiting the test still passes, but
+	// doesn't cover the wake-up.
+	time.Sleep(10 * time.Millisecond)
+
+	cancel()
+	close(release)
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("first Version() error = %v, want %v", err, context.Canceled)
+	}
+
+	select {
+	case err := <-second:
+		if err != nil {
+			t.Errorf("second Version() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("second Version() did not return after the first was canceled")
+	}
+}
+
+func TestLCM_VersionExchange(t *testing.T) {
+	version := withChecksum(lcm.Message{byte(lcm.Command), 0x03, byte(lcm.Fversion), 0, 1, 2})
+	enter := fmt.Sprintf("in %#x", withChecksum(lcm.Message{byte(lcm.Command), 0x01, byte(lcm.Fbutton), byte(lcm.Enter)}))
+	tests := []struct {
+		name  string
+		reply lcmtest.ReplyFunc
+		want  []string // Tapped frames.
+	}{
+		{"Ack then version", lcmtest.ReplyVersion(0, 1, 2), []string{
+			"out 0xf001130105",
+			"in 0xf101130005",
+			"in 0xf0031300010209",
+		}},
+		{"Version then ack", func(msg lcm.Message) []byte {
+			return append(append([]byte(nil), version...), lcmtest.ReplyOk(msg)...)
+		}, []string{
+			"out 0xf001130105",
+			"in 0xf0031300010209",
+			"in 0xf101130005",
+		}},
+		{"Lost ack", func(msg lcm.Message) []byte {
+			return version
+		}, []string{
+			"out 0xf001130105",
+			"in 0xf0031300010209",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu     sync.Mutex
+				frames []string
+			)
+			tap := func(dir lcm.Direction, msg lcm.Message, _ time.Time) {
+				mu.Lock()
+				defer mu.Unlock()
+				frames = append(frames, fmt.Sprintf("%v %#x", dir, []byte(msg)))
+			}
+
+			// The version must not be acknowledged, even when
+			// acknowledging commands.
+			m, f := openFake(t, tt.reply, lcm.WithTap(tap), lcm.EnableProtocolAckReply())
+			buttons := m.Buttons()
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			major, minor, patch, err := m.Version(ctx)
+			if err != nil {
+				t.Fatalf("Version() error = %v", err)
+			}
+			if major != 0 || minor != 1 || patch != 2 {
+				t.Errorf("Version() = %d.%d.%d, want 0.1.2", major, minor, patch)
+			}
+
+			// The version is passed on to Recv.
+			got, err := m.RecvContext(ctx)
+			if err != nil || got.Function() != lcm.Fversion {
+				t.Errorf("RecvContext() = %v, %v, want version", got, err)
+			}
+
+			// A button pushed now is read after the trailing
+			// frames, if any.
+			f.PushButton(lcm.Enter)
+			select {
+			case <-buttons:
+			case <-ctx.Done():
+				t.Fatal("timed out waiting for button")
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			tapped := frames
+			for i, fr := range frames {
+				if fr == enter {
+					tapped = frames[:i]
+					break
+				}
+			}
+			if fmt.Sprint(tapped) != fmt.Sprint(tt.want) {
+				t.Errorf("frames = %v, want %v", tapped, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package lcm
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const progressEmpty = ' '
+
+// ProgressBar renders a progress bar on the display line, e.g.:
+//
+//	[####      ] 40%
+//
+// The bar is filled with Sym.Block. The fraction is clamped to [0, 1].
+// An optional label can be shown in front of the bar, it reduces the
+// width of the bar.
+func ProgressBar(line DisplayLine, fraction float64, label string) (Message, error) {
+	fraction = clampFraction(fraction)
+
+	prefix := label
+	if prefix != "" {
+		prefix += " "
+	}
+	pct := fmt.Sprintf("%3d%%", int(fraction*100))
+
+	width := 16 - len(prefix) - len(pct) - 2
+	if width < 1 {
+		return nil, fmt.Errorf("label: %w", ErrTextTooLong)
+	}
+	filled := int(fraction * float64(width))
+
+	text := prefix + "[" +
+		strings.Repeat(string([]byte{Sym.Block}), filled) +
+		strings.Repeat(string(progressEmpty), width-filled) +
+		"]" + pct
+	return SetDisplay(line, 0, text)
+}
+
+// gaugeMinWidth is the minimum width of the Gauge bar, the label is
+// cut short to make room for it.
+const gaugeMinWidth = 4
+
+// Gauge renders a label and a bar with a right-aligned percentage on
+// the display line, e.g. for CPU usage:
+//
+//	CPU [#####  ]85%
+//
+// The bar uses the width that remains after the label and percentage.
+// The fraction is clamped to [0, 1]. Unlike ProgressBar, a label that is
+// too long does not result in an error, instead it is cut short to fit.
+// The label is transliterated first, see Transliterate.
+func Gauge(line DisplayLine, label string, fraction float64) (Message, error) {
+	fraction = clampFraction(fraction)
+	pct := fmt.Sprintf("%d%%", int(fraction*100))
+
+	// Transliterate so that the label is measured and cut in bytes,
+	// the same unit as the display.
+	label = Transliterate(label)
+
+	// Room for the label and the space separating it from the bar.
+	room := 16 - len(pct) - 2 - gaugeMinWidth
+	if len(label)+1 > room {
+		label = label[:room-1]
+	}
+	prefix := label
+	if prefix != "" {
+		prefix += " "
+	}
+
+	width := 16 - len(prefix) - len(pct) - 2
+	if width < 0 {
+		width = 0
+	}
+	filled := int(fraction * float64(width))
+
+	text := prefix + "[" +
+		strings.Repeat(string([]byte{Sym.Block}), filled) +
+		strings.Repeat(string(progressEmpty), width-filled) +
+		"]" + pct
+	return SetDisplay(line, 0, text)
+}
+
+func clampFraction(f float64) float64 {
+	switch {
+	case math.IsNaN(f), f < 0:
+		return 0
+	case f > 1:
+		return 1
+	}
+	return f
+}
+
+// DefaultSpinnerFrames are the frames used by a Spinner by default.
+const DefaultSpinnerFrames = `|/-\`
+
+// DefaultSpinnerDelay is the suggested delay between Spinner frames.
+const DefaultSpinnerDelay = 200 * time.Millisecond
+
+// Spinner shows an animation in a single cell of the display, e.g. as
+// feedback during long operations. Only the spinner cell is written,
+// the rest of the line is left intact.
+type Spinner struct {
+	line   DisplayLine
+	column int
+	frames []byte
+	i      int
+}
+
+// NewSpinner returns a spinner in the given position, cycling through
+// frames (one character per frame). When frames is empty,
+// DefaultSpinnerFrames is used.
+func NewSpinner(line DisplayLine, column int, frames string) (*Spinner, error) {
+	if frames == "" {
+		frames = DefaultSpinnerFrames
+	}
+	// Validate the position.
+	if _, err := SetDisplayCharacter(line, column, 0); err != nil {
+		return nil, err
+	}
+	return &Spinner{line: line, column: column, frames: []byte(frames)}, nil
+}
+
+// Next returns the message for showing the next frame.
+func (s *Spinner) Next() Message {
+	b, _ := SetDisplayCharacter(s.line, s.column, s.frames[s.i])
+	s.i = (s.i + 1) % len(s.frames)
+	return b
+}
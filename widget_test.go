@@ -0,0 +1,99 @@
+package lcm
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestProgressBar(t *testing.T) {
+	type args struct {
+		line     DisplayLine
+		fraction float64
+		label    string
+	}
+	tests := []struct {
+		name     string
+		args     args
+		wantText string
+		wantErr  bool
+	}{
+		{name: "Test 0%", args: args{line: DisplayBottom, fraction: 0}, wantText: "[          ]  0%"},
+		{name: "Test 40%", args: args{line: DisplayBottom, fraction: 0.4}, wantText: "[####      ] 40%"},
+		{name: "Test 50%", args: args{line: DisplayBottom, fraction: 0.5}, wantText: "[#####     ] 50%"},
+		{name: "Test 100%", args: args{line: DisplayBottom, fraction: 1}, wantText: "[##########]100%"},
+		{name: "Test clamp", args: args{line: DisplayBottom, fraction: 1.5}, wantText: "[##########]100%"},
+		{name: "Test clamp negative", args: args{line: DisplayBottom, fraction: -1}, wantText: "[          ]  0%"},
+		{name: "Test label", args: args{line: DisplayBottom, fraction: 0.5, label: "BK"}, wantText: "BK [###    ] 50%"},
+		{name: "Test label too long", args: args{line: DisplayBottom, fraction: 0.5, label: "BACKUP TO"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotRaw, err := ProgressBar(tt.args.line, tt.args.fraction, tt.args.label)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ProgressBar() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			wantRaw, _ := SetDisplay(tt.args.line, 0, tt.wantText)
+			if fmt.Sprintf("%#x", gotRaw) != fmt.Sprintf("%#x", wantRaw) {
+				t.Errorf("ProgressBar() = %q, want %q", gotRaw[5:], tt.wantText)
+			}
+		})
+	}
+}
+
+func TestGauge(t *testing.T) {
+	tests := []struct {
+		label    string
+		fraction float64
+		want     string
+	}{
+		{"CPU", 0.85, "CPU [#####  ]85%"},
+		{"CPU", 0.05, "CPU [        ]5%"},
+		{"MEM", 1, "MEM [######]100%"},
+		{"", 0.5, "[#####      ]50%"},
+		{"Load", -1, "Load [       ]0%"},
+		{"Temperature", 0.85, "Temper [### ]85%"},
+		{"Temperature", 1, "Tempe [####]100%"},
+		{"ääääääää", 1, "aaaaa [####]100%"},
+		{"Température", 0.5, "Temper [##  ]50%"},
+	}
+	for _, tt := range tests {
+		got, err := Gauge(DisplayTop, tt.label, tt.fraction)
+		if err != nil {
+			t.Errorf("Gauge(%q, %v) error = %v", tt.label, tt.fraction, err)
+			continue
+		}
+		if string(got[5:]) != tt.want {
+			t.Errorf("Gauge(%q, %v) = %q, want %q", tt.label, tt.fraction, got[5:], tt.want)
+		}
+	}
+
+	if _, err := Gauge(DisplayLine(2), "CPU", 0.5); err == nil {
+		t.Error("Gauge(DisplayLine(2)) error = nil, want error")
+	}
+}
+
+func TestSpinner(t *testing.T) {
+	s, err := NewSpinner(DisplayBottom, 15, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []byte(`|/-\|/`)
+	for i, w := range want {
+		got := s.Next()
+		wantRaw, _ := SetDisplayCharacter(DisplayBottom, 15, w)
+		if fmt.Sprintf("%#x", got) != fmt.Sprintf("%#x", wantRaw) {
+			t.Errorf("Next() #%d = %#x, want %#x", i, got, wantRaw)
+		}
+	}
+
+	for _, column := range []int{-1, 16} {
+		if _, err := NewSpinner(DisplayBottom, column, ""); err == nil {
+			t.Errorf("NewSpinner(DisplayBottom, %d) error = nil, want error", column)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package lcm_test
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mafredri/lcm"
+	"github.com/mafredri/lcm/lcmtest"
+)
+
+func TestLCM_Write(t *testing.T) {
+	waiting := make(chan struct{}, 1)
+	first := true
+	m, f := openFake(t, func(msg lcm.Message) []byte {
+		if msg.Function() == lcm.Fon && first {
+			// Don't reply, the host will wait and retry.
+			first = false
+			waiting <- struct{}{}
+			return nil
+		}
+		return lcmtest.ReplyOk(msg)
+	})
+
+	errc := make(chan error, 1)
+	go func() { errc <- m.Send(lcm.DisplayOn) }()
+	<-waiting
+
+	raw := append(lcm.Message(nil), lcm.DisplayStatus...)
+	raw = append(raw, lcm.Checksum(raw))
+	err := m.Write(raw)
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err = <-errc; err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	// The raw write must not happen before the command is complete.
+	lastOn, status := -1, -1
+	for i, msg := range f.Received() {
+		switch msg.Function() {
+		case lcm.Fon:
+			lastOn = i
+		case lcm.Fstatus:
+			status = i
+		}
+	}
+	if status < lastOn {
+		t.Errorf("raw write at %d, before command completed at %d", status, lastOn)
+	}
+}
+
+// singleWriter fails the test if Write is called concurrently.
+type singleWriter struct {
+	*lcmtest.FakeMCU
+	t      *testing.T
+	active int32
+}
+
+func (w *singleWriter) Write(p []byte) (int, error) {
+	if atomic.AddInt32(&w.active, 1) > 1 {
+		w.t.Error("concurrent Write")
+	}
+	defer atomic.AddInt32(&w.active, -1)
+	time.Sleep(50 * time.Microsecond) // Widen the window.
+	return w.FakeMCU.Write(p)
+}
+
+// TestLCM_SingleWriter verifies that all writes (sends, raw writes and
+// flushes, including automatic flushes on timeout) are made by a single
+// writer.
+func TestLCM_SingleWriter(t *testing.T) {
+	f := lcmtest.NewFakeMCU()
+	n := 0
+	f.SetReplyFunc(func(msg lcm.Message) []byte {
+		if msg.Function() == lcm.Fon {
+			n++
+			if n%3 == 0 {
+				return nil // Lost reply, triggers a flush.
+			}
+		}
+		return lcmtest.ReplyOk(msg)
+	})
+	m := lcm.OpenConn(&singleWriter{FakeMCU: f, t: t})
+	t.Cleanup(func() { m.Close() })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			_ = m.Send(lcm.DisplayOn)
+		}()
+		go func() {
+			defer wg.Done()
+			_ = m.FlushMCU()
+		}()
+		go func() {
+			defer wg.Done()
+			_ = m.Write(withChecksum(lcm.DisplayStatus))
+		}()
+	}
+	wg.Wait()
+}
+
+// shortConn writes at most max bytes per call, zero writes nothing.
+type shortConn struct {
+	*lcmtest.FakeMCU
+	max int
+}
+
+func (c shortConn) Write(p []byte) (int, error) {
+	if len(p) > c.max {
+		p = p[:c.max]
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	return c.FakeMCU.Write(p)
+}
+
+func TestLCM_ShortWrite(t *testing.T) {
+	tests := []struct {
+		name    string
+		max     int
+		wantErr error
+	}{
+		{"Partial", 2, nil},
+		{"Nothing written", 0, io.ErrShortWrite},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := lcmtest.NewFakeMCU()
+			m := lcm.OpenConn(shortConn{FakeMCU: f, max: tt.max})
+			t.Cleanup(func() { m.Close() })
+
+			_, err := m.TrySend(lcm.DisplayOn, lcm.WithRetryLimit(1), lcm.WithReplyTimeout(10*time.Millisecond))
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("TrySend() error = %v", err)
+				}
+				got := f.Received()
+				if len(got) != 1 || string(got[0]) != string(lcm.DisplayOn) {
+					t.Errorf("received = %#x, want %#x", got, lcm.DisplayOn)
+				}
+				return
+			}
+
+			var serr *lcm.SendError
+			if !errors.As(err, &serr) || !errors.Is(err, tt.wantErr) {
+				t.Fatalf("TrySend() error = %v, want *lcm.SendError wrapping %v", err, tt.wantErr)
+			}
+		})
+	}
+}